@@ -1,18 +1,21 @@
 package common
 
 import (
+	"crypto/tls"
 	"errors"
+	"fmt"
 
 	"go.uber.org/cadence/client"
-	m "go.uber.org/cadence/.gen/go/cadence"
 
 	"github.com/uber-go/tally"
-	//"github.com/uber/tchannel-go"
-	//"github.com/uber/tchannel-go/thrift"
 	"go.uber.org/cadence/.gen/go/cadence/workflowserviceclient"
+	apiv1 "go.uber.org/cadence/.gen/proto/api/v1"
+	"go.uber.org/cadence/compatibility"
+	"go.uber.org/cadence/encoded"
 	"go.uber.org/yarpc"
-	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/transport/grpc"
 	"go.uber.org/yarpc/transport/tchannel"
+	"google.golang.org/grpc/credentials"
 )
 
 const (
@@ -20,13 +23,23 @@ const (
 	cadenceFrontendService = "cadence-frontend"
 )
 
+const (
+	// TransportTChannel dials the cadence frontend over tchannel, this is the default
+	TransportTChannel = "tchannel"
+	// TransportGRPC dials the cadence frontend over grpc, optionally with TLS
+	TransportGRPC = "grpc"
+)
+
 // WorkflowClientBuilder build client to cadence service
 type WorkflowClientBuilder struct {
-	tchanClient    thrift.TChanClient
+	dispatcher     *yarpc.Dispatcher
 	hostPort       string
+	transport      string
+	tlsConfig      *tls.Config
 	domain         string
 	clientIdentity string
 	metricsScope   tally.Scope
+	dataConverter  encoded.DataConverter
 }
 
 // NewBuilder creates a new WorkflowClientBuilder
@@ -40,6 +53,18 @@ func (b *WorkflowClientBuilder) SetHostPort(hostport string) *WorkflowClientBuil
 	return b
 }
 
+// SetTransport sets the rpc transport for the builder, either TransportTChannel or TransportGRPC
+func (b *WorkflowClientBuilder) SetTransport(transport string) *WorkflowClientBuilder {
+	b.transport = transport
+	return b
+}
+
+// SetTLSConfig sets the TLS config used by the grpc transport
+func (b *WorkflowClientBuilder) SetTLSConfig(tlsConfig *tls.Config) *WorkflowClientBuilder {
+	b.tlsConfig = tlsConfig
+	return b
+}
+
 // SetDomain sets the domain for the builder
 func (b *WorkflowClientBuilder) SetDomain(domain string) *WorkflowClientBuilder {
 	b.domain = domain
@@ -58,6 +83,12 @@ func (b *WorkflowClientBuilder) SetMetricsScope(metricsScope tally.Scope) *Workf
 	return b
 }
 
+// SetDataConverter sets the data converter used to encode and decode payloads
+func (b *WorkflowClientBuilder) SetDataConverter(dataConverter encoded.DataConverter) *WorkflowClientBuilder {
+	b.dataConverter = dataConverter
+	return b
+}
+
 // BuildCadenceClient builds a client to cadence service
 func (b *WorkflowClientBuilder) BuildCadenceClient() (client.Client, error) {
 	service, err := b.BuildServiceClient()
@@ -66,7 +97,7 @@ func (b *WorkflowClientBuilder) BuildCadenceClient() (client.Client, error) {
 	}
 
 	return client.NewClient(
-		service, b.domain, &client.Options{Identity: b.clientIdentity, MetricsScope: b.metricsScope}), nil
+		service, b.domain, &client.Options{Identity: b.clientIdentity, MetricsScope: b.metricsScope, DataConverter: b.dataConverter}), nil
 }
 
 // BuildCadenceDomainClient builds a domain client to cadence service
@@ -80,29 +111,65 @@ func (b *WorkflowClientBuilder) BuildCadenceDomainClient() (client.DomainClient,
 		service, &client.Options{Identity: b.clientIdentity, MetricsScope: b.metricsScope}), nil
 }
 
-// BuildServiceClient builds a thrift service client to cadence service
-func (b *WorkflowClientBuilder) BuildServiceClient() (m.TChanWorkflowService, error) {
+// BuildServiceClient builds a rpc service client to cadence service
+func (b *WorkflowClientBuilder) BuildServiceClient() (workflowserviceclient.Interface, error) {
 	if err := b.build(); err != nil {
 		return nil, err
 	}
 
-	return m.NewTChanWorkflowServiceClient(b.tchanClient), nil
+	clientConfig := b.dispatcher.ClientConfig(cadenceFrontendService)
+	if b.transport == TransportGRPC {
+		return compatibility.NewThrift2ProtoAdapter(
+			apiv1.NewDomainAPIYARPCClient(clientConfig),
+			apiv1.NewWorkflowAPIYARPCClient(clientConfig),
+			apiv1.NewWorkerAPIYARPCClient(clientConfig),
+			apiv1.NewVisibilityAPIYARPCClient(clientConfig),
+		), nil
+	}
+	return workflowserviceclient.New(clientConfig), nil
 }
 
 func (b *WorkflowClientBuilder) build() error {
-	if b.tchanClient != nil {
+	if b.dispatcher != nil {
 		return nil
 	}
 	if len(b.hostPort) == 0 {
 		return errors.New("HostPort must be valid")
 	}
 
-	tchan, err := tchannel.NewChannel(cadenceClientName, nil)
-	if err != nil {
+	var outbound yarpc.Outbounds
+	switch b.transport {
+	case "", TransportTChannel:
+		if b.tlsConfig != nil {
+			return errors.New("TLS is only supported with the grpc transport")
+		}
+		ch, err := tchannel.NewChannelTransport(tchannel.ServiceName(cadenceClientName))
+		if err != nil {
+			return err
+		}
+		outbound = yarpc.Outbounds{
+			cadenceFrontendService: {Unary: ch.NewSingleOutbound(b.hostPort)},
+		}
+	case TransportGRPC:
+		var opts []grpc.OutboundOption
+		if b.tlsConfig != nil {
+			opts = append(opts, grpc.OutboundCredentials(credentials.NewTLS(b.tlsConfig)))
+		}
+		outbound = yarpc.Outbounds{
+			cadenceFrontendService: {Unary: grpc.NewTransport().NewSingleOutbound(b.hostPort, opts...)},
+		}
+	default:
+		return fmt.Errorf("unknown transport %s", b.transport)
+	}
+
+	dispatcher := yarpc.NewDispatcher(yarpc.Config{
+		Name:      cadenceClientName,
+		Outbounds: outbound,
+	})
+	if err := dispatcher.Start(); err != nil {
 		return err
 	}
 
-	opts := &thrift.ClientOptions{HostPort: b.hostPort}
-	b.tchanClient = thrift.NewClient(tchan, cadenceFrontendService, opts)
+	b.dispatcher = dispatcher
 	return nil
 }
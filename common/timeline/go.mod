@@ -0,0 +1,5 @@
+module github.com/venkat1109/cadence-codelab/common/timeline
+
+go 1.16
+
+require go.uber.org/cadence v0.18.2
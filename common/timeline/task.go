@@ -0,0 +1,45 @@
+package timeline
+
+import (
+	s "go.uber.org/cadence/.gen/go/shared"
+)
+
+// Statuses of tasks and task groups
+const (
+	StatusScheduled = "s"
+	StatusRunning   = "r"
+	StatusCompleted = "c"
+	StatusFailed    = "f"
+	StatusTimedOut  = "t"
+	StatusCanceled  = "ca"
+)
+
+type (
+	// TaskStatus type for status value.
+	TaskStatus string
+
+	// Task models an activity, timer or child workflow of a workflow history.
+	Task struct {
+		ID        int64
+		Name      string
+		Status    TaskStatus
+		StartTime int64 // unix nanos of the scheduling event
+		EndTime   int64 // unix nanos of the closing event, zero while open
+		SubTasks  []*Task
+	}
+
+	// TaskGroupStatus type for status of a task group.
+	TaskGroupStatus string
+
+	// TaskGroup models the tasks of a workflow execution.
+	TaskGroup struct {
+		ID        string
+		RunID     string
+		Status    TaskGroupStatus
+		StartTime int64 // unix nanos of the workflow start
+		EndTime   int64 // unix nanos of the workflow close, zero while open
+		Tasks     []*Task
+		TaskMap   map[int64]*Task
+		History   *s.History
+	}
+)
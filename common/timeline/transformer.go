@@ -0,0 +1,267 @@
+// Package timeline transforms workflow histories into task trees. It is its own module
+// so that both the CLI and the eatsapp webserver can import it.
+package timeline
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strconv"
+
+	s "go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/client"
+)
+
+type (
+	// TransformFunc type defining the signature of transform function.
+	transformFunc func(event *s.HistoryEvent, tasks *TaskGroup) error
+
+	// TaskGroupExecution implements object to transform a workflow history into a TaskGroup.
+	TaskGroupExecution struct {
+		client       client.Client
+		transformers map[s.EventType]transformFunc
+	}
+)
+
+// NewTaskGroupExecution returns a new instance of TaskGroupExecution.
+func NewTaskGroupExecution(c client.Client) *TaskGroupExecution {
+	obj := &TaskGroupExecution{
+		client:       c,
+		transformers: make(map[s.EventType]transformFunc),
+	}
+
+	obj.transformers[s.EventTypeWorkflowExecutionStarted] = obj.tfWorkflowExecutionStarted
+	obj.transformers[s.EventTypeWorkflowExecutionCompleted] = obj.tfWorkflowExecutionCompleted
+	obj.transformers[s.EventTypeWorkflowExecutionContinuedAsNew] = obj.tfWorkflowExecutionCompleted
+	obj.transformers[s.EventTypeWorkflowExecutionFailed] = obj.tfWorkflowExecutionFailed
+	obj.transformers[s.EventTypeWorkflowExecutionTimedOut] = obj.tfWorkflowExecutionTimedOut
+	obj.transformers[s.EventTypeWorkflowExecutionCanceled] = obj.tfWorkflowExecutionCanceled
+	obj.transformers[s.EventTypeWorkflowExecutionTerminated] = obj.tfWorkflowExecutionTerminated
+
+	obj.transformers[s.EventTypeActivityTaskScheduled] = obj.tfActivityTaskScheduled
+	obj.transformers[s.EventTypeActivityTaskStarted] = obj.tfActivityTaskStarted
+	obj.transformers[s.EventTypeActivityTaskCompleted] = obj.tfActivityTaskCompleted
+	obj.transformers[s.EventTypeActivityTaskFailed] = obj.tfActivityTaskFailed
+	obj.transformers[s.EventTypeActivityTaskTimedOut] = obj.tfActivityTaskTimedOut
+	obj.transformers[s.EventTypeActivityTaskCanceled] = obj.tfActivityTaskCanceled
+
+	obj.transformers[s.EventTypeStartChildWorkflowExecutionInitiated] = obj.tfStartChildWorkflowExecutionInitiated
+	obj.transformers[s.EventTypeChildWorkflowExecutionStarted] = obj.tfChildWorkflowExecutionStarted
+	obj.transformers[s.EventTypeChildWorkflowExecutionCompleted] = obj.tfChildWorkflowExecutionCompleted
+	obj.transformers[s.EventTypeChildWorkflowExecutionFailed] = obj.tfChildWorkflowExecutionFailed
+	obj.transformers[s.EventTypeChildWorkflowExecutionTimedOut] = obj.tfChildWorkflowExecutionTimedOut
+	obj.transformers[s.EventTypeChildWorkflowExecutionCanceled] = obj.tfChildWorkflowExecutionCanceled
+	obj.transformers[s.EventTypeChildWorkflowExecutionTerminated] = obj.tfChildWorkflowExecutionTerminated
+
+	obj.transformers[s.EventTypeTimerStarted] = obj.tfTimerStarted
+	obj.transformers[s.EventTypeTimerFired] = obj.tfTimerFired
+	obj.transformers[s.EventTypeTimerCanceled] = obj.tfTimerCanceled
+	return obj
+}
+
+// Transform converts a workflow execution history into a TaskGroup structure,
+// child workflows become the sub tasks of the task that started them.
+func (h *TaskGroupExecution) Transform(workflowID string, runID string) (*TaskGroup, error) {
+	tasks := &TaskGroup{
+		ID:      workflowID,
+		RunID:   runID,
+		Tasks:   make([]*Task, 0),
+		TaskMap: make(map[int64]*Task),
+		History: &s.History{},
+	}
+	ctx := context.Background()
+	history := h.client.GetWorkflowHistory(ctx, workflowID, runID, false, s.HistoryEventFilterTypeAllEvent)
+	for history.HasNext() {
+		event, err := history.Next()
+		if err != nil {
+			return nil, err
+		}
+		tasks.History.Events = append(tasks.History.Events, event)
+	}
+
+	for _, event := range tasks.History.Events {
+		transFunc, found := h.transformers[event.GetEventType()]
+		if !found {
+			continue
+		}
+
+		err := transFunc(event, tasks)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tasks.TaskMap = nil
+	return tasks, nil
+}
+
+func (h *TaskGroupExecution) tfActivityTaskScheduled(event *s.HistoryEvent, tasks *TaskGroup) error {
+	name := event.ActivityTaskScheduledEventAttributes.ActivityType.GetName()
+	return h.createTask(event, shortName(name), tasks)
+}
+
+func (h *TaskGroupExecution) tfActivityTaskStarted(event *s.HistoryEvent, tasks *TaskGroup) error {
+	id := event.ActivityTaskStartedEventAttributes.GetScheduledEventId()
+	return h.setTaskStatus(tasks, id, StatusRunning)
+}
+
+func (h *TaskGroupExecution) tfActivityTaskCompleted(event *s.HistoryEvent, tasks *TaskGroup) error {
+	id := event.ActivityTaskCompletedEventAttributes.GetScheduledEventId()
+	return h.closeTask(event, tasks, id, StatusCompleted)
+}
+
+func (h *TaskGroupExecution) tfActivityTaskFailed(event *s.HistoryEvent, tasks *TaskGroup) error {
+	id := event.ActivityTaskFailedEventAttributes.GetScheduledEventId()
+	return h.closeTask(event, tasks, id, StatusFailed)
+}
+
+func (h *TaskGroupExecution) tfActivityTaskTimedOut(event *s.HistoryEvent, tasks *TaskGroup) error {
+	id := event.ActivityTaskTimedOutEventAttributes.GetScheduledEventId()
+	return h.closeTask(event, tasks, id, StatusTimedOut)
+}
+
+func (h *TaskGroupExecution) tfActivityTaskCanceled(event *s.HistoryEvent, tasks *TaskGroup) error {
+	id := event.ActivityTaskCanceledEventAttributes.GetScheduledEventId()
+	return h.closeTask(event, tasks, id, StatusCanceled)
+}
+
+func (h *TaskGroupExecution) tfStartChildWorkflowExecutionInitiated(event *s.HistoryEvent, tasks *TaskGroup) error {
+	name := event.StartChildWorkflowExecutionInitiatedEventAttributes.WorkflowType.GetName()
+	return h.createTask(event, shortName(name), tasks)
+}
+
+func (h *TaskGroupExecution) tfChildWorkflowExecutionStarted(event *s.HistoryEvent, tasks *TaskGroup) error {
+	id := event.ChildWorkflowExecutionStartedEventAttributes.GetInitiatedEventId()
+	task, found := tasks.TaskMap[id]
+	if !found {
+		return errors.New("Could not find StartChildWorkflowExecutionInitiated event: " + strconv.FormatInt(id, 10))
+	}
+
+	execution := event.ChildWorkflowExecutionStartedEventAttributes.WorkflowExecution
+	taskGroup, err := h.Transform(execution.GetWorkflowId(), execution.GetRunId())
+	if err != nil {
+		return err
+	}
+
+	task.SubTasks = taskGroup.Tasks
+	task.Status = StatusRunning
+	return nil
+}
+
+func (h *TaskGroupExecution) tfChildWorkflowExecutionCompleted(event *s.HistoryEvent, tasks *TaskGroup) error {
+	id := event.ChildWorkflowExecutionCompletedEventAttributes.GetInitiatedEventId()
+	return h.closeTask(event, tasks, id, StatusCompleted)
+}
+
+func (h *TaskGroupExecution) tfChildWorkflowExecutionFailed(event *s.HistoryEvent, tasks *TaskGroup) error {
+	id := event.ChildWorkflowExecutionFailedEventAttributes.GetInitiatedEventId()
+	return h.closeTask(event, tasks, id, StatusFailed)
+}
+
+func (h *TaskGroupExecution) tfChildWorkflowExecutionTimedOut(event *s.HistoryEvent, tasks *TaskGroup) error {
+	id := event.ChildWorkflowExecutionTimedOutEventAttributes.GetInitiatedEventId()
+	return h.closeTask(event, tasks, id, StatusTimedOut)
+}
+
+func (h *TaskGroupExecution) tfChildWorkflowExecutionCanceled(event *s.HistoryEvent, tasks *TaskGroup) error {
+	id := event.ChildWorkflowExecutionCanceledEventAttributes.GetInitiatedEventId()
+	return h.closeTask(event, tasks, id, StatusCanceled)
+}
+
+func (h *TaskGroupExecution) tfChildWorkflowExecutionTerminated(event *s.HistoryEvent, tasks *TaskGroup) error {
+	id := event.ChildWorkflowExecutionTerminatedEventAttributes.GetInitiatedEventId()
+	return h.closeTask(event, tasks, id, StatusFailed)
+}
+
+func (h *TaskGroupExecution) tfTimerStarted(event *s.HistoryEvent, tasks *TaskGroup) error {
+	name := "timer " + event.TimerStartedEventAttributes.GetTimerId()
+	if err := h.createTask(event, name, tasks); err != nil {
+		return err
+	}
+	return h.setTaskStatus(tasks, event.GetEventId(), StatusRunning)
+}
+
+func (h *TaskGroupExecution) tfTimerCanceled(event *s.HistoryEvent, tasks *TaskGroup) error {
+	id := event.TimerCanceledEventAttributes.GetStartedEventId()
+	return h.closeTask(event, tasks, id, StatusCanceled)
+}
+
+func (h *TaskGroupExecution) tfTimerFired(event *s.HistoryEvent, tasks *TaskGroup) error {
+	id := event.TimerFiredEventAttributes.GetStartedEventId()
+	return h.closeTask(event, tasks, id, StatusCompleted)
+}
+
+func (h *TaskGroupExecution) tfWorkflowExecutionStarted(event *s.HistoryEvent, tasks *TaskGroup) error {
+	tasks.Status = StatusRunning
+	tasks.StartTime = event.GetTimestamp()
+	return nil
+}
+
+// tfWorkflowExecutionCompleted also closes runs that continued as new, the next run
+// has a history of its own
+func (h *TaskGroupExecution) tfWorkflowExecutionCompleted(event *s.HistoryEvent, tasks *TaskGroup) error {
+	return h.closeGroup(event, tasks, StatusCompleted)
+}
+
+func (h *TaskGroupExecution) tfWorkflowExecutionFailed(event *s.HistoryEvent, tasks *TaskGroup) error {
+	return h.closeGroup(event, tasks, StatusFailed)
+}
+
+func (h *TaskGroupExecution) tfWorkflowExecutionTimedOut(event *s.HistoryEvent, tasks *TaskGroup) error {
+	return h.closeGroup(event, tasks, StatusTimedOut)
+}
+
+func (h *TaskGroupExecution) tfWorkflowExecutionCanceled(event *s.HistoryEvent, tasks *TaskGroup) error {
+	return h.closeGroup(event, tasks, StatusCanceled)
+}
+
+func (h *TaskGroupExecution) tfWorkflowExecutionTerminated(event *s.HistoryEvent, tasks *TaskGroup) error {
+	return h.closeGroup(event, tasks, StatusFailed)
+}
+
+func (h *TaskGroupExecution) createTask(event *s.HistoryEvent, name string, tasks *TaskGroup) error {
+	task := &Task{
+		ID:        event.GetEventId(),
+		Name:      name,
+		Status:    StatusScheduled,
+		StartTime: event.GetTimestamp(),
+	}
+
+	tasks.TaskMap[task.ID] = task
+	tasks.Tasks = append(tasks.Tasks, task)
+
+	return nil
+}
+
+func (h *TaskGroupExecution) setTaskStatus(tasks *TaskGroup, id int64, status TaskStatus) error {
+	task, found := tasks.TaskMap[id]
+	if !found {
+		return errors.New("Could not find the event opening the task: " + strconv.FormatInt(id, 10))
+	}
+
+	task.Status = status
+	return nil
+}
+
+func (h *TaskGroupExecution) closeTask(event *s.HistoryEvent, tasks *TaskGroup, id int64, status TaskStatus) error {
+	if err := h.setTaskStatus(tasks, id, status); err != nil {
+		return err
+	}
+
+	tasks.TaskMap[id].EndTime = event.GetTimestamp()
+	return nil
+}
+
+func (h *TaskGroupExecution) closeGroup(event *s.HistoryEvent, tasks *TaskGroup, status TaskGroupStatus) error {
+	tasks.Status = status
+	tasks.EndTime = event.GetTimestamp()
+	return nil
+}
+
+// shortName strips the package path of a registered activity or workflow name
+func shortName(name string) string {
+	if ext := path.Ext(name); len(ext) > 1 {
+		return ext[1:]
+	}
+	return name
+}
@@ -1,15 +1,16 @@
 package common
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 
-	"go.uber.org/cadence/internal"
-	//"go.uber.org/cadence/.gen/go/cadence"
+	"github.com/uber-go/tally"
+	"go.uber.org/cadence/.gen/go/cadence/workflowserviceclient"
 	s "go.uber.org/cadence/.gen/go/shared"
 	"go.uber.org/cadence/common"
+	"go.uber.org/cadence/internal"
 	"go.uber.org/zap"
-	"github.com/uber-go/tally"
 	"gopkg.in/yaml.v2"
 )
 
@@ -20,7 +21,7 @@ const (
 type (
 	// .
 	Runtime struct {
-		Service m.TChanWorkflowService
+		Service workflowserviceclient.Interface
 		Scope   tally.Scope
 		Logger  *zap.Logger
 		Config  Configuration
@@ -86,7 +87,7 @@ func (h *Runtime) doInit() {
 		Name:                                   common.StringPtr(h.Config.DomainName),
 		Description:                            common.StringPtr("domain for cadence sample code"),
 		WorkflowExecutionRetentionPeriodInDays: common.Int32Ptr(3)}
-	err = domainClient.Register(request)
+	err = domainClient.Register(context.Background(), request)
 	if err != nil {
 		if _, ok := err.(*s.DomainAlreadyExistsError); !ok {
 			panic(err)
@@ -106,7 +107,7 @@ func (h *Runtime) StartWorkflow(options internal.StartWorkflowOptions, workflow
 		panic(err)
 	}
 
-	we, err := workflowClient.StartWorkflow(options, workflow, args...)
+	we, err := workflowClient.StartWorkflow(context.Background(), options, workflow, args...)
 	if err != nil {
 		h.Logger.Error("Failed to create workflow", zap.Error(err))
 		panic("Failed to create workflow.")
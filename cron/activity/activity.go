@@ -2,49 +2,170 @@ package activity
 
 import (
 	"context"
-	"errors"
-	"go.uber.org/cadence"
+	"fmt"
+	"sync"
 	"time"
+
+	cadence "go.uber.org/cadence/activity"
+	"go.uber.org/zap"
 )
 
 const (
 	heartbeatInterval = 10 * time.Second
+	// SimulateJob is the job run when a schedule does not name one, it
+	// simulates two minutes of work
+	SimulateJob          = "simulate"
+	simulateSteps        = 120
+	simulateStepDuration = time.Second
+	defaultJobTimeout    = 10 * time.Minute
+)
+
+type (
+	// Job is the unit of work run by the Cron activity. It is either a command, or
+	// split into steps run by a registered handler with progress checkpointed after
+	// every step.
+	Job struct {
+		Name    string        // name of the job, and of its handler when it has no command
+		Steps   int           // number of steps the job is split into
+		Timeout time.Duration // start to close timeout of each attempt
+
+		Command string            // command run by the job instead of a handler, optional
+		Args    []string          // arguments of the command
+		Env     map[string]string // environment of the command, added to the worker's
+
+		ScheduledTime time.Time // nominal fire time of the run, set by the Cron workflow
+	}
+
+	// JobProgress is the checkpoint recorded with every heartbeat
+	JobProgress struct {
+		Attempt        int32 // attempt that recorded the checkpoint
+		CompletedSteps int   // steps completed so far
+
+		// set for command jobs
+		ExitCode     int    // exit status of the command
+		Stdout       string // tail of the stdout of the command
+		Stderr       string // tail of the stderr of the command
+		OutputDigest string // hex sha256 digest of the digests of the whole stdout and stderr
+	}
+
+	// JobHandler runs one step of a job. It must return when ctx is done.
+	JobHandler func(ctx context.Context, job *Job, step int) error
+)
+
+var (
+	handlersLock sync.RWMutex
+	handlers     = map[string]JobHandler{SimulateJob: simulateStep}
 )
 
 func init() {
-	cadence.RegisterActivity(Cron)
+	cadence.Register(Cron)
 }
 
-// Cron implements the cron activity
-func Cron(ctx context.Context) error {
-	return errors.New("not implemented")
+// RegisterJob registers the handler running the steps of the jobs with the given name
+func RegisterJob(name string, handler JobHandler) {
+	handlersLock.Lock()
+	defer handlersLock.Unlock()
+	handlers[name] = handler
 }
 
-// isDone returns true if the activity is done or cancelled
-// activity is done if the background task is done
-// activity is cancelled if the context is marked as done
-func isDone(ctx context.Context, doneC chan struct{}) bool {
-	select {
-	case <-ctx.Done():
-		return true
-	case <-doneC:
-		return true
-	default:
-		return false
+// WithDefaults returns the job with its unset fields defaulted
+func (j Job) WithDefaults() Job {
+	if len(j.Name) == 0 && len(j.Command) == 0 {
+		j.Name = SimulateJob
+		if j.Steps == 0 {
+			j.Steps = simulateSteps
+		}
+	}
+	if j.Steps == 0 {
+		j.Steps = 1
 	}
+	if j.Timeout == 0 {
+		j.Timeout = defaultJobTimeout
+	}
+	return j
 }
 
-// doWork is a dummy implementation of a
-// cron job. In real world, this method
+// Cron implements the cron activity. It runs the steps of the job one after the
+// other, heartbeating its progress every heartbeatInterval. When the activity is
+// retried, possibly on another worker, it resumes after the last checkpointed step.
+func Cron(ctx context.Context, job Job) (JobProgress, error) {
+	logger := cadence.GetLogger(ctx)
+	info := cadence.GetInfo(ctx)
+
+	if len(job.Command) > 0 {
+		return runCommand(ctx, &job)
+	}
+
+	handlersLock.RLock()
+	handler, ok := handlers[job.Name]
+	handlersLock.RUnlock()
+	if !ok {
+		return JobProgress{}, fmt.Errorf("no handler registered for job %s", job.Name)
+	}
+
+	var progress JobProgress
+	if cadence.HasHeartbeatDetails(ctx) {
+		if err := cadence.GetHeartbeatDetails(ctx, &progress); err != nil {
+			logger.Warn("Ignoring unreadable checkpoint", zap.Error(err))
+			progress = JobProgress{}
+		} else {
+			logger.Info("Resuming job from checkpoint", zap.String("job", job.Name),
+				zap.Int("completedSteps", progress.CompletedSteps), zap.Int32("checkpointAttempt", progress.Attempt))
+		}
+	}
+	progress.Attempt = info.Attempt
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for progress.CompletedSteps < job.Steps {
+		doneC := make(chan error, 1)
+		go func(step int) {
+			doneC <- handler(ctx, &job, step)
+		}(progress.CompletedSteps)
+
+		if err := waitForStep(ctx, ticker.C, doneC, &progress); err != nil {
+			// checkpoint what is done so that a retry does not redo it
+			cadence.RecordHeartbeat(ctx, progress)
+			if ctx.Err() != nil {
+				logger.Info("Job canceled", zap.String("job", job.Name), zap.Int("completedSteps", progress.CompletedSteps))
+				return progress, ctx.Err()
+			}
+			return progress, err
+		}
+		progress.CompletedSteps++
+	}
+
+	cadence.RecordHeartbeat(ctx, progress)
+	logger.Info("Job completed", zap.String("job", job.Name), zap.Int("steps", job.Steps), zap.Time("scheduledTime", job.ScheduledTime))
+	return progress, nil
+}
+
+// waitForStep waits for the running step to finish, heartbeating the progress on every tick.
+// The activity context is done when the activity is canceled or times out.
+func waitForStep(ctx context.Context, tickC <-chan time.Time, doneC chan error, progress *JobProgress) error {
+	for {
+		select {
+		case err := <-doneC:
+			return err
+		case <-tickC:
+			cadence.RecordHeartbeat(ctx, *progress)
+		case <-ctx.Done():
+			// give the step a chance to stop before reporting
+			<-doneC
+			return ctx.Err()
+		}
+	}
+}
+
+// simulateStep simulates one step of a cron job. In real world, this method
 // could run a actual background task
-func doWork(stopC chan struct{}, doneC chan struct{}) {
+func simulateStep(ctx context.Context, job *Job, step int) error {
 	select {
-	// simulate two mins of work
-	case <-time.After(time.Minute):
-		close(doneC)
-		return
-	case <-stopC:
+	case <-time.After(simulateStepDuration):
+		return nil
+	case <-ctx.Done():
 		// stop work and quit if we are asked to
-		return
+		return ctx.Err()
 	}
 }
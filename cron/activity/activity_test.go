@@ -0,0 +1,97 @@
+package activity
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/cadence/testsuite"
+)
+
+// recordSteps registers a job handler under name recording the steps it runs,
+// the step failStep fails
+func recordSteps(name string, failStep int) *[]int {
+	var lock sync.Mutex
+	var steps []int
+	RegisterJob(name, func(ctx context.Context, job *Job, step int) error {
+		lock.Lock()
+		defer lock.Unlock()
+		steps = append(steps, step)
+		if step == failStep {
+			return errors.New("step failed")
+		}
+		return nil
+	})
+	return &steps
+}
+
+func TestJobWithDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		job  Job
+		want Job
+	}{
+		{name: "simulated", want: Job{Name: SimulateJob, Steps: simulateSteps, Timeout: defaultJobTimeout}},
+		{name: "handler", job: Job{Name: "export"}, want: Job{Name: "export", Steps: 1, Timeout: defaultJobTimeout}},
+		{
+			name: "command",
+			job:  Job{Command: "/bin/true", Timeout: time.Minute},
+			want: Job{Command: "/bin/true", Steps: 1, Timeout: time.Minute},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.job.WithDefaults())
+		})
+	}
+}
+
+func TestCronRunsEveryStep(t *testing.T) {
+	steps := recordSteps("test-all-steps", -1)
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+
+	value, err := env.ExecuteActivity(Cron, Job{Name: "test-all-steps", Steps: 3})
+	require.NoError(t, err)
+	var progress JobProgress
+	require.NoError(t, value.Get(&progress))
+	assert.Equal(t, 3, progress.CompletedSteps)
+	assert.Equal(t, []int{0, 1, 2}, *steps)
+}
+
+func TestCronResumesFromCheckpoint(t *testing.T) {
+	steps := recordSteps("test-resume", -1)
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+	// the previous attempt checkpointed three steps
+	env.SetHeartbeatDetails(JobProgress{CompletedSteps: 3})
+
+	value, err := env.ExecuteActivity(Cron, Job{Name: "test-resume", Steps: 5})
+	require.NoError(t, err)
+	var progress JobProgress
+	require.NoError(t, value.Get(&progress))
+	assert.Equal(t, 5, progress.CompletedSteps)
+	assert.Equal(t, []int{3, 4}, *steps)
+}
+
+func TestCronStopsAtFailedStep(t *testing.T) {
+	steps := recordSteps("test-fail", 1)
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+
+	_, err := env.ExecuteActivity(Cron, Job{Name: "test-fail", Steps: 3})
+	assert.Error(t, err)
+	assert.Equal(t, []int{0, 1}, *steps)
+}
+
+func TestCronUnknownJob(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+
+	_, err := env.ExecuteActivity(Cron, Job{Name: "test-unregistered", Steps: 1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+package activity
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	sdk "go.uber.org/cadence"
+	cadence "go.uber.org/cadence/activity"
+	"go.uber.org/zap"
+)
+
+const (
+	// CommandFailedReason is the reason of the error returned when a command exits with
+	// a non zero status, its details are the JobProgress of the command
+	CommandFailedReason = "cron_command_failed"
+	// outputTailSize is the number of trailing bytes of stdout and stderr kept in the progress
+	outputTailSize = 4 * 1024
+)
+
+// Environment variables set for the commands run by cron jobs
+const (
+	EnvJobName       = "CRON_JOB_NAME"
+	EnvScheduledTime = "CRON_SCHEDULED_TIME"
+	EnvAttempt       = "CRON_ATTEMPT"
+)
+
+// tailWriter keeps the last bytes written to it and a digest of everything written
+type tailWriter struct {
+	sync.Mutex
+	tail   []byte
+	digest hash.Hash
+}
+
+func newTailWriter() *tailWriter {
+	return &tailWriter{digest: sha256.New()}
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	w.digest.Write(p)
+	w.tail = append(w.tail, p...)
+	if len(w.tail) > outputTailSize {
+		w.tail = append([]byte(nil), w.tail[len(w.tail)-outputTailSize:]...)
+	}
+	return len(p), nil
+}
+
+func (w *tailWriter) String() string {
+	w.Lock()
+	defer w.Unlock()
+	return string(w.tail)
+}
+
+func (w *tailWriter) sum() []byte {
+	w.Lock()
+	defer w.Unlock()
+	return w.digest.Sum(nil)
+}
+
+// runCommand runs the command of the job, heartbeating the tails of its stdout and
+// stderr every heartbeatInterval. A command is not resumable, a retried attempt runs
+// it from the start.
+func runCommand(ctx context.Context, job *Job) (JobProgress, error) {
+	logger := cadence.GetLogger(ctx)
+	progress := JobProgress{Attempt: cadence.GetInfo(ctx).Attempt}
+
+	cmd := exec.CommandContext(ctx, job.Command, job.Args...)
+	cmd.Env = append(os.Environ(),
+		EnvJobName+"="+job.Name,
+		EnvScheduledTime+"="+job.ScheduledTime.Format(time.RFC3339),
+		fmt.Sprintf("%s=%d", EnvAttempt, progress.Attempt))
+	for k, v := range job.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	stdout, stderr := newTailWriter(), newTailWriter()
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	snapshot := func() JobProgress {
+		progress.Stdout = stdout.String()
+		progress.Stderr = stderr.String()
+		return progress
+	}
+
+	logger.Info("Running command", zap.String("job", job.Name), zap.String("command", job.Command), zap.Strings("args", job.Args))
+	if err := cmd.Start(); err != nil {
+		return progress, err
+	}
+	doneC := make(chan error, 1)
+	go func() {
+		doneC <- cmd.Wait()
+	}()
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	var err error
+	for waiting := true; waiting; {
+		select {
+		case err = <-doneC:
+			waiting = false
+		case <-ticker.C:
+			cadence.RecordHeartbeat(ctx, snapshot())
+		}
+	}
+
+	snapshot()
+	digest := sha256.New()
+	digest.Write(stdout.sum())
+	digest.Write(stderr.sum())
+	progress.OutputDigest = hex.EncodeToString(digest.Sum(nil))
+	progress.CompletedSteps = 1
+
+	if ctx.Err() != nil {
+		logger.Info("Command canceled", zap.String("job", job.Name))
+		return progress, ctx.Err()
+	}
+	if err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			return progress, err
+		}
+		progress.ExitCode = -1
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			progress.ExitCode = status.ExitStatus()
+		}
+		logger.Warn("Command failed", zap.String("job", job.Name), zap.Int("exitCode", progress.ExitCode))
+		return progress, sdk.NewCustomError(CommandFailedReason, progress)
+	}
+	logger.Info("Command completed", zap.String("job", job.Name), zap.String("outputDigest", progress.OutputDigest))
+	return progress, nil
+}
@@ -0,0 +1,89 @@
+package activity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdk "go.uber.org/cadence"
+	"go.uber.org/cadence/testsuite"
+)
+
+func TestTailWriter(t *testing.T) {
+	w := newTailWriter()
+	output := strings.Repeat("a", outputTailSize+100) + "end"
+	for i := 0; i < len(output); i += 1000 {
+		end := i + 1000
+		if end > len(output) {
+			end = len(output)
+		}
+		n, err := w.Write([]byte(output[i:end]))
+		require.NoError(t, err)
+		assert.Equal(t, end-i, n)
+	}
+
+	assert.Len(t, w.String(), outputTailSize)
+	assert.True(t, strings.HasSuffix(w.String(), "end"))
+	// the digest covers the whole output, not only its tail
+	sum := sha256.Sum256([]byte(output))
+	assert.Equal(t, sum[:], w.sum())
+}
+
+func TestRunCommand(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+	job := Job{
+		Name:          "export",
+		Command:       "/bin/sh",
+		Args:          []string{"-c", `printf "$CRON_JOB_NAME $CRON_SCHEDULED_TIME $BUCKET"`},
+		Env:           map[string]string{"BUCKET": "exports"},
+		ScheduledTime: time.Date(2026, 3, 2, 2, 0, 0, 0, time.UTC),
+	}
+
+	value, err := env.ExecuteActivity(Cron, job)
+	require.NoError(t, err)
+	var progress JobProgress
+	require.NoError(t, value.Get(&progress))
+	assert.Equal(t, "export 2026-03-02T02:00:00Z exports", progress.Stdout)
+	assert.Empty(t, progress.Stderr)
+	assert.Zero(t, progress.ExitCode)
+	assert.Equal(t, 1, progress.CompletedSteps)
+
+	stdoutSum := sha256.Sum256([]byte(progress.Stdout))
+	stderrSum := sha256.Sum256(nil)
+	digest := sha256.Sum256(append(stdoutSum[:], stderrSum[:]...))
+	assert.Equal(t, hex.EncodeToString(digest[:]), progress.OutputDigest)
+}
+
+func TestRunCommandFailure(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+	job := Job{Name: "export", Command: "/bin/sh", Args: []string{"-c", "echo partial; echo disk full >&2; exit 3"}}
+
+	_, err := env.ExecuteActivity(Cron, job)
+	customErr, ok := err.(*sdk.CustomError)
+	require.True(t, ok, "expected a custom error, got %v", err)
+	assert.Equal(t, CommandFailedReason, customErr.Reason())
+
+	// the details carry the exit code and output of the command
+	var progress JobProgress
+	require.NoError(t, customErr.Details(&progress))
+	assert.Equal(t, 3, progress.ExitCode)
+	assert.Equal(t, "partial\n", progress.Stdout)
+	assert.Equal(t, "disk full\n", progress.Stderr)
+	assert.NotEmpty(t, progress.OutputDigest)
+}
+
+func TestRunCommandNotFound(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestActivityEnvironment()
+
+	_, err := env.ExecuteActivity(Cron, Job{Name: "export", Command: "/nonexistent/export"})
+	require.Error(t, err)
+	_, ok := err.(*sdk.CustomError)
+	assert.False(t, ok, "a command that cannot start is not a command failure")
+}
@@ -0,0 +1,119 @@
+package jobs
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const holidayLayout = "2006-01-02"
+
+type (
+	// holidayFile is the YAML holiday calendar, for example
+	//
+	//   holidays:
+	//   - {date: 2026-12-25, name: Christmas}
+	//   - {date: 2026-12-26, name: Boxing day}
+	holidayFile struct {
+		Holidays []struct {
+			Date string `yaml:"date"`
+			Name string `yaml:"name"`
+		} `yaml:"holidays"`
+	}
+)
+
+// LoadHolidays reads the dates of a holiday calendar, either an iCalendar (.ics)
+// file of all day events or a YAML file
+func LoadHolidays(path string) ([]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var days []string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ics":
+		days, err = parseICS(data)
+	default:
+		days, err = parseHolidayYAML(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid holiday calendar %s: %v", path, err)
+	}
+	sort.Strings(days)
+	return days, nil
+}
+
+func parseHolidayYAML(data []byte) ([]string, error) {
+	var file holidayFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	var days []string
+	for _, h := range file.Holidays {
+		if _, err := time.Parse(holidayLayout, h.Date); err != nil {
+			return nil, fmt.Errorf("invalid date %s of %s, expected %s", h.Date, h.Name, holidayLayout)
+		}
+		days = append(days, h.Date)
+	}
+	return days, nil
+}
+
+// parseICS returns the days covered by the events of an iCalendar file. DTEND is
+// exclusive, an event without DTEND covers the day of its DTSTART.
+func parseICS(data []byte) ([]string, error) {
+	var days []string
+	var start, end time.Time
+	inEvent := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "BEGIN:VEVENT":
+			inEvent = true
+			start, end = time.Time{}, time.Time{}
+		case line == "END:VEVENT":
+			inEvent = false
+			if start.IsZero() {
+				return nil, errors.New("event without DTSTART")
+			}
+			if end.IsZero() || !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+			for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+				days = append(days, day.Format(holidayLayout))
+			}
+		case inEvent && (strings.HasPrefix(line, "DTSTART") || strings.HasPrefix(line, "DTEND")):
+			i := strings.LastIndex(line, ":")
+			if i < 0 {
+				return nil, fmt.Errorf("invalid line %s", line)
+			}
+			day, err := parseICSDate(line[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			if strings.HasPrefix(line, "DTSTART") {
+				start = day
+			} else {
+				end = day
+			}
+		}
+	}
+	return days, scanner.Err()
+}
+
+// parseICSDate parses the date of a DATE or DATE-TIME value, ignoring its time
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %s", value)
+	}
+	return time.Parse("20060102", value[:8])
+}
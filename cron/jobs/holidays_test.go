@@ -0,0 +1,125 @@
+package jobs
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseICS(t *testing.T) {
+	tests := []struct {
+		name    string
+		ics     string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "all day event",
+			ics:  "BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:Christmas\nDTSTART;VALUE=DATE:20261225\nDTEND;VALUE=DATE:20261226\nEND:VEVENT\nEND:VCALENDAR\n",
+			want: []string{"2026-12-25"},
+		},
+		{
+			name: "event over several days",
+			ics:  "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20261230\nDTEND;VALUE=DATE:20270102\nEND:VEVENT\n",
+			want: []string{"2026-12-30", "2026-12-31", "2027-01-01"},
+		},
+		{
+			name: "event without end",
+			ics:  "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20260704\nEND:VEVENT\n",
+			want: []string{"2026-07-04"},
+		},
+		{
+			name: "date time values",
+			ics:  "BEGIN:VEVENT\r\nDTSTART:20261124T090000Z\r\nDTEND:20261124T170000Z\r\nEND:VEVENT\r\n",
+			want: []string{"2026-11-24"},
+		},
+		{
+			name: "dates outside events are ignored",
+			ics:  "BEGIN:VCALENDAR\nDTSTART:20260101\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:20260501\nEND:VEVENT\nEND:VCALENDAR\n",
+			want: []string{"2026-05-01"},
+		},
+		{
+			name:    "event without start",
+			ics:     "BEGIN:VEVENT\nSUMMARY:Unknown\nEND:VEVENT\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid date",
+			ics:     "BEGIN:VEVENT\nDTSTART;VALUE=DATE:2026\nEND:VEVENT\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days, err := parseICS([]byte(tt.ics))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, days)
+		})
+	}
+}
+
+func TestLoadHolidays(t *testing.T) {
+	dir, err := ioutil.TempDir("", "holidays")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "yaml",
+			file:    "holidays.yaml",
+			content: "holidays:\n- {date: 2026-12-26, name: Boxing day}\n- {date: 2026-12-25, name: Christmas}\n",
+			want:    []string{"2026-12-25", "2026-12-26"},
+		},
+		{
+			name:    "ics",
+			file:    "holidays.ICS",
+			content: "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20261231\nEND:VEVENT\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:20261225\nEND:VEVENT\n",
+			want:    []string{"2026-12-25", "2026-12-31"},
+		},
+		{
+			name:    "invalid yaml date",
+			file:    "invalid.yaml",
+			content: "holidays:\n- {date: 25/12/2026, name: Christmas}\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid yaml",
+			file:    "broken.yaml",
+			content: "holidays: [",
+			wantErr: true,
+		},
+		{
+			name:    "invalid ics",
+			file:    "broken.ics",
+			content: "BEGIN:VEVENT\nEND:VEVENT\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days, err := LoadHolidays(writeTestFile(t, dir, tt.file, tt.content))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, days)
+		})
+	}
+
+	_, err = LoadHolidays(dir + "/missing.yaml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,194 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/cron/activity"
+	"github.com/venkat1109/cadence-codelab/cron/notify"
+	"github.com/venkat1109/cadence-codelab/cron/workflow"
+	"go.uber.org/cadence"
+	"gopkg.in/yaml.v2"
+)
+
+// workflowIDPrefix prefixes the id of the Cron workflow of every job
+const workflowIDPrefix = "cron_"
+
+type (
+	// File is the jobs file read by the cron worker and starter
+	File struct {
+		Jobs []*Spec `yaml:"jobs"`
+	}
+
+	// Spec defines a cron job, for example
+	//
+	//   jobs:
+	//   - name: nightly-export
+	//     schedule: "0 2 * * *"
+	//     timezone: America/New_York
+	//     hostgroups: [hostgroup-1, hostgroup-2]
+	//     command: /usr/local/bin/export
+	//     args: [--full]
+	//     env: {EXPORT_BUCKET: exports}
+	//     timeout: 30m
+	//     retry: {initial_interval: 30s, max_attempts: 3}
+	//     alert_after_failures: 2
+	//     notifiers:
+	//     - {type: webhook, url: "https://alerts.example.com/cron"}
+	//     - {type: file, path: /var/log/cron-alerts.jsonl}
+	//     blackouts: [{start: "11:00", end: "14:00"}, {start: "17:00", end: "21:00"}]
+	//     business_days: true
+	//     holidays: cron/holidays.ics
+	//     blackout_policy: defer
+	Spec struct {
+		Name       string            `yaml:"name"`
+		Schedule   string            `yaml:"schedule"`  // cron expression
+		Frequency  time.Duration     `yaml:"frequency"` // used when schedule is empty
+		TimeZone   string            `yaml:"timezone"`
+		Jitter     time.Duration     `yaml:"jitter"`
+		Hostgroups []string          `yaml:"hostgroups"`
+		Command    string            `yaml:"command"`
+		Args       []string          `yaml:"args"`
+		Env        map[string]string `yaml:"env"`
+		Timeout    time.Duration     `yaml:"timeout"`
+		Retry      *RetrySpec        `yaml:"retry"`
+		Mode       string            `yaml:"mode"`
+		Overlap    string            `yaml:"overlap"`
+		Notifiers  []notify.Config   `yaml:"notifiers"`
+		AlertAfter int               `yaml:"alert_after_failures"`
+
+		Blackouts      []workflow.BlackoutWindow `yaml:"blackouts"`
+		BusinessDays   bool                      `yaml:"business_days"`
+		Holidays       string                    `yaml:"holidays"` // ICS or YAML holiday calendar
+		BlackoutPolicy string                    `yaml:"blackout_policy"`
+	}
+
+	// RetrySpec is the retry policy of the command of a job
+	RetrySpec struct {
+		InitialInterval    time.Duration `yaml:"initial_interval"`
+		BackoffCoefficient float64       `yaml:"backoff"`
+		MaximumInterval    time.Duration `yaml:"max_interval"`
+		MaximumAttempts    int32         `yaml:"max_attempts"`
+		ExpirationInterval time.Duration `yaml:"expiration"`
+	}
+)
+
+// Load reads and validates a jobs file
+func Load(path string) (*File, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid jobs file %s: %v", path, err)
+	}
+	if len(file.Jobs) == 0 {
+		return nil, fmt.Errorf("no job defined in %s", path)
+	}
+
+	names := make(map[string]bool)
+	for _, spec := range file.Jobs {
+		if len(spec.Name) == 0 {
+			return nil, errors.New("every job needs a name")
+		}
+		if names[spec.Name] {
+			return nil, fmt.Errorf("job %s is defined twice", spec.Name)
+		}
+		names[spec.Name] = true
+		schedule, err := spec.CronSchedule()
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %v", spec.Name, err)
+		}
+		if err := schedule.Validate(); err != nil {
+			return nil, fmt.Errorf("job %s: %v", spec.Name, err)
+		}
+	}
+	return &file, nil
+}
+
+// Hostgroups returns the hostgroups the jobs run on
+func (f *File) Hostgroups() []string {
+	var hostgroups []string
+	seen := make(map[string]bool)
+	for _, spec := range f.Jobs {
+		for _, hg := range spec.Hostgroups {
+			if !seen[hg] {
+				seen[hg] = true
+				hostgroups = append(hostgroups, hg)
+			}
+		}
+	}
+	return hostgroups
+}
+
+// WorkflowID returns the id of the Cron workflow running the job
+func (s *Spec) WorkflowID() string {
+	return WorkflowID(s.Name)
+}
+
+// WorkflowID returns the id of the Cron workflow running the named job
+func WorkflowID(name string) string {
+	return workflowIDPrefix + name
+}
+
+// CronSchedule returns the schedule of the Cron workflow running the job
+func (s *Spec) CronSchedule() (*workflow.CronSchedule, error) {
+	if len(s.Command) == 0 {
+		return nil, errors.New("command is required")
+	}
+	schedule := &workflow.CronSchedule{
+		Expression: s.Schedule,
+		Frequency:  s.Frequency,
+		TimeZone:   s.TimeZone,
+		Jitter:     s.Jitter,
+		Hostgroups: s.Hostgroups,
+		Job: activity.Job{
+			Name:    s.Name,
+			Timeout: s.Timeout,
+			Command: s.Command,
+			Args:    s.Args,
+			Env:     s.Env,
+		},
+		Mode:    workflow.ExecutionMode(s.Mode),
+		Overlap: workflow.OverlapPolicy(s.Overlap),
+
+		Notifiers:          s.Notifiers,
+		AlertAfterFailures: s.AlertAfter,
+	}
+	if s.Retry != nil {
+		if s.Retry.MaximumAttempts <= 0 && s.Retry.ExpirationInterval <= 0 {
+			return nil, errors.New("retry needs max_attempts or expiration")
+		}
+		schedule.JobRetry = &cadence.RetryPolicy{
+			InitialInterval:    s.Retry.InitialInterval,
+			BackoffCoefficient: s.Retry.BackoffCoefficient,
+			MaximumInterval:    s.Retry.MaximumInterval,
+			MaximumAttempts:    s.Retry.MaximumAttempts,
+			ExpirationInterval: s.Retry.ExpirationInterval,
+		}
+		if schedule.JobRetry.InitialInterval == 0 {
+			schedule.JobRetry.InitialInterval = 10 * time.Second
+		}
+		if schedule.JobRetry.BackoffCoefficient == 0 {
+			schedule.JobRetry.BackoffCoefficient = 2
+		}
+	}
+	if len(s.Blackouts) > 0 || s.BusinessDays || len(s.Holidays) > 0 {
+		schedule.Calendar = &workflow.Calendar{
+			Blackouts:    s.Blackouts,
+			BusinessDays: s.BusinessDays,
+			Policy:       workflow.BlackoutPolicy(s.BlackoutPolicy),
+		}
+		if len(s.Holidays) > 0 {
+			holidays, err := LoadHolidays(s.Holidays)
+			if err != nil {
+				return nil, err
+			}
+			schedule.Calendar.Holidays = holidays
+		}
+	}
+	return schedule, nil
+}
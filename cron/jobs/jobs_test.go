@@ -0,0 +1,118 @@
+package jobs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/venkat1109/cadence-codelab/cron/workflow"
+)
+
+// writeTestFile writes the content to the named file in dir and returns its path
+func writeTestFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jobs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			content: `jobs:
+- name: export
+  schedule: "0 2 * * *"
+  timezone: America/New_York
+  hostgroups: [db-1]
+  command: /usr/local/bin/export
+  args: [--full]
+  timeout: 30m
+- name: cleanup
+  frequency: 1h
+  hostgroups: [web-1, web-2]
+  command: /usr/local/bin/cleanup
+  mode: parallel
+`,
+		},
+		{name: "invalid yaml", content: "jobs: [", wantErr: "invalid jobs file"},
+		{name: "no job", content: "jobs: []", wantErr: "no job defined"},
+		{name: "missing name", content: "jobs:\n- {schedule: \"* * * * *\", hostgroups: [a], command: x}", wantErr: "every job needs a name"},
+		{
+			name:    "duplicate name",
+			content: "jobs:\n- {name: a, frequency: 1m, hostgroups: [a], command: x}\n- {name: a, frequency: 1m, hostgroups: [a], command: x}",
+			wantErr: "job a is defined twice",
+		},
+		{name: "missing command", content: "jobs:\n- {name: a, frequency: 1m, hostgroups: [a]}", wantErr: "job a: command is required"},
+		{name: "missing hostgroup", content: "jobs:\n- {name: a, frequency: 1m, command: x}", wantErr: "job a: at least one hostgroup is required"},
+		{name: "invalid expression", content: "jobs:\n- {name: a, schedule: \"0 25 * * *\", hostgroups: [a], command: x}", wantErr: "job a:"},
+		{
+			name:    "retry without limit",
+			content: "jobs:\n- {name: a, frequency: 1m, hostgroups: [a], command: x, retry: {initial_interval: 1s}}",
+			wantErr: "job a: retry needs max_attempts or expiration",
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestFile(t, dir, fmt.Sprintf("jobs-%d.yaml", i), tt.content)
+			file, err := Load(path)
+			if len(tt.wantErr) > 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, file.Jobs, 2)
+			assert.Equal(t, []string{"db-1", "web-1", "web-2"}, file.Hostgroups())
+			assert.Equal(t, "cron_export", file.Jobs[0].WorkflowID())
+		})
+	}
+
+	_, err = Load(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestCronSchedule(t *testing.T) {
+	spec := &Spec{
+		Name:       "export",
+		Schedule:   "0 2 * * *",
+		TimeZone:   "America/New_York",
+		Hostgroups: []string{"db-1"},
+		Command:    "/usr/local/bin/export",
+		Args:       []string{"--full"},
+		Env:        map[string]string{"BUCKET": "exports"},
+		Timeout:    30 * time.Minute,
+		Retry:      &RetrySpec{MaximumAttempts: 3},
+		Overlap:    "buffer_one",
+	}
+
+	schedule, err := spec.CronSchedule()
+	require.NoError(t, err)
+	require.NoError(t, schedule.Validate())
+	assert.Equal(t, "0 2 * * *", schedule.Expression)
+	assert.Equal(t, "America/New_York", schedule.TimeZone)
+	assert.Equal(t, "export", schedule.Job.Name)
+	assert.Equal(t, "/usr/local/bin/export", schedule.Job.Command)
+	assert.Equal(t, []string{"--full"}, schedule.Job.Args)
+	assert.Equal(t, map[string]string{"BUCKET": "exports"}, schedule.Job.Env)
+	assert.Equal(t, 30*time.Minute, schedule.Job.Timeout)
+	assert.Equal(t, workflow.OverlapBufferOne, schedule.Overlap)
+	assert.Nil(t, schedule.Calendar)
+	// unset retry settings get defaults
+	require.NotNil(t, schedule.JobRetry)
+	assert.Equal(t, 10*time.Second, schedule.JobRetry.InitialInterval)
+	assert.Equal(t, 2.0, schedule.JobRetry.BackoffCoefficient)
+	assert.Equal(t, int32(3), schedule.JobRetry.MaximumAttempts)
+}
@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// webhookNotifier posts the events as json
+	webhookNotifier struct {
+		url string
+	}
+
+	// smtpNotifier mails the events
+	smtpNotifier struct {
+		addr string
+		from string
+		to   []string
+		auth smtp.Auth
+	}
+
+	// fileNotifier appends the events to a local file as json lines
+	fileNotifier struct {
+		path string
+	}
+)
+
+// fileLock serializes the appends of the file notifiers of a worker
+var fileLock sync.Mutex
+
+func (n *webhookNotifier) Notify(ctx context.Context, event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned %s", n.url, resp.Status)
+	}
+	return nil
+}
+
+func newSMTPNotifier(config *Config) *smtpNotifier {
+	n := &smtpNotifier{addr: config.SMTPAddr, from: config.From, to: config.To}
+	if len(config.Username) > 0 {
+		host, _, err := net.SplitHostPort(config.SMTPAddr)
+		if err != nil {
+			host = config.SMTPAddr
+		}
+		n.auth = smtp.PlainAuth("", config.Username, os.Getenv(config.PasswordEnv), host)
+	}
+	return n
+}
+
+func (n *smtpNotifier) Notify(ctx context.Context, event *Event) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&msg, "Subject: [cron] %s\r\n", event.summary())
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "Event:      %s\r\n", event.Type)
+	fmt.Fprintf(&msg, "Workflow:   %s\r\n", event.WorkflowID)
+	fmt.Fprintf(&msg, "Fire time:  %s\r\n", event.FireTime.Format(time.RFC3339))
+	if len(event.Hostgroup) > 0 {
+		fmt.Fprintf(&msg, "Hostgroup:  %s\r\n", event.Hostgroup)
+	}
+	if len(event.Error) > 0 {
+		fmt.Fprintf(&msg, "Error:      %s\r\n", event.Error)
+		fmt.Fprintf(&msg, "Exit code:  %d\r\n", event.ExitCode)
+	}
+	if len(event.OutputDigest) > 0 {
+		fmt.Fprintf(&msg, "Output:     %s\r\n", event.OutputDigest)
+	}
+
+	// smtp.SendMail does not take a context, run it aside so that cancellation is honored
+	errC := make(chan error, 1)
+	go func() {
+		errC <- smtp.SendMail(n.addr, n.auth, n.from, n.to, msg.Bytes())
+	}()
+	select {
+	case err := <-errC:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (n *fileNotifier) Notify(ctx context.Context, event *Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	fileLock.Lock()
+	defer fileLock.Unlock()
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
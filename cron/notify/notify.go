@@ -0,0 +1,156 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	cadence "go.uber.org/cadence/activity"
+	"go.uber.org/zap"
+)
+
+// EventType is the kind of a cron alert
+type EventType string
+
+const (
+	// EventFailure is raised when the job of a run failed on a hostgroup
+	EventFailure EventType = "failure"
+	// EventTimeout is raised when the job of a run timed out on a hostgroup
+	EventTimeout EventType = "timeout"
+	// EventConsecutiveFailures is raised when a number of runs in a row failed
+	EventConsecutiveFailures EventType = "consecutive_failures"
+	// EventRecovery is raised when a run succeeded after failed ones
+	EventRecovery EventType = "recovery"
+)
+
+// Notifier types
+const (
+	TypeWebhook = "webhook"
+	TypeSMTP    = "smtp"
+	TypeFile    = "file"
+)
+
+type (
+	// Event is a cron alert sent to the notifiers
+	Event struct {
+		Type                EventType `json:"type"`
+		WorkflowID          string    `json:"workflowId"`
+		Job                 string    `json:"job"`
+		Hostgroup           string    `json:"hostgroup,omitempty"`
+		FireTime            time.Time `json:"fireTime"`
+		Error               string    `json:"error,omitempty"`
+		ExitCode            int       `json:"exitCode,omitempty"`
+		OutputDigest        string    `json:"outputDigest,omitempty"`
+		ConsecutiveFailures int       `json:"consecutiveFailures,omitempty"`
+	}
+
+	// Config configures a notifier of a cron job
+	Config struct {
+		Type   string      `yaml:"type"`   // webhook, smtp or file
+		Events []EventType `yaml:"events"` // events sent to the notifier, all by default
+
+		URL string `yaml:"url"` // webhook receiving the events as json
+
+		SMTPAddr    string   `yaml:"smtp_addr"` // host:port of the SMTP server
+		From        string   `yaml:"from"`
+		To          []string `yaml:"to"`
+		Username    string   `yaml:"username"`     // optional, enables plain auth
+		PasswordEnv string   `yaml:"password_env"` // worker environment variable holding the password
+
+		Path string `yaml:"path"` // file the events are appended to as json lines
+	}
+
+	// Notifier sends cron alerts
+	Notifier interface {
+		Notify(ctx context.Context, event *Event) error
+	}
+)
+
+func init() {
+	cadence.Register(Notify)
+}
+
+// Validate checks the notifier configuration
+func (c *Config) Validate() error {
+	for _, e := range c.Events {
+		switch e {
+		case EventFailure, EventTimeout, EventConsecutiveFailures, EventRecovery:
+		default:
+			return fmt.Errorf("invalid event type %s", e)
+		}
+	}
+	switch c.Type {
+	case TypeWebhook:
+		if len(c.URL) == 0 {
+			return errors.New("webhook notifier needs a url")
+		}
+	case TypeSMTP:
+		if len(c.SMTPAddr) == 0 || len(c.From) == 0 || len(c.To) == 0 {
+			return errors.New("smtp notifier needs smtp_addr, from and to")
+		}
+	case TypeFile:
+		if len(c.Path) == 0 {
+			return errors.New("file notifier needs a path")
+		}
+	default:
+		return fmt.Errorf("invalid notifier type '%s', must be %s, %s or %s", c.Type, TypeWebhook, TypeSMTP, TypeFile)
+	}
+	return nil
+}
+
+// Wants returns whether the events of the given type are sent to the notifier
+func (c *Config) Wants(eventType EventType) bool {
+	if len(c.Events) == 0 {
+		return true
+	}
+	for _, e := range c.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// New returns the notifier of a configuration
+func New(config *Config) (Notifier, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	switch config.Type {
+	case TypeWebhook:
+		return &webhookNotifier{url: config.URL}, nil
+	case TypeSMTP:
+		return newSMTPNotifier(config), nil
+	default:
+		return &fileNotifier{path: config.Path}, nil
+	}
+}
+
+// Notify implements the notify activity, it sends an event to a notifier
+func Notify(ctx context.Context, config Config, event Event) error {
+	notifier, err := New(&config)
+	if err != nil {
+		return err
+	}
+	if err := notifier.Notify(ctx, &event); err != nil {
+		cadence.GetLogger(ctx).Warn("Notification failed", zap.String("type", config.Type),
+			zap.String("event", string(event.Type)), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// summary returns a one line description of the event
+func (e *Event) summary() string {
+	switch e.Type {
+	case EventConsecutiveFailures:
+		return fmt.Sprintf("cron job %s failed %d runs in a row", e.Job, e.ConsecutiveFailures)
+	case EventRecovery:
+		return fmt.Sprintf("cron job %s recovered", e.Job)
+	case EventTimeout:
+		return fmt.Sprintf("cron job %s timed out on %s", e.Job, e.Hostgroup)
+	default:
+		return fmt.Sprintf("cron job %s failed on %s", e.Job, e.Hostgroup)
+	}
+}
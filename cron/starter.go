@@ -1,27 +1,59 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"time"
+
 	"github.com/venkat1109/cadence-codelab/common"
+	"github.com/venkat1109/cadence-codelab/cron/jobs"
 	"github.com/venkat1109/cadence-codelab/cron/workflow"
-	"go.uber.org/cadence"
-	"time"
+	s "go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/client"
+	"go.uber.org/zap"
 )
 
 func main() {
+	jobsFile := flag.String("jobs", "cron/jobs.yaml", "jobs file")
+	flag.Parse()
 
 	runtime := common.NewRuntime()
+	file, err := jobs.Load(*jobsFile)
+	if err != nil {
+		runtime.Logger.Fatal("Failed to load jobs", zap.Error(err))
+	}
+	workflowClient, err := runtime.Builder.BuildCadenceClient()
+	if err != nil {
+		runtime.Logger.Fatal("Failed to build cadence client", zap.Error(err))
+	}
 
-	workflowOptions := cadence.StartWorkflowOptions{
+	for _, spec := range file.Jobs {
+		schedule, err := spec.CronSchedule()
+		if err != nil {
+			runtime.Logger.Fatal("Invalid job", zap.String("job", spec.Name), zap.Error(err))
+		}
+		if err := startOrUpdate(workflowClient, spec.WorkflowID(), schedule); err != nil {
+			runtime.Logger.Fatal("Failed to start or update job", zap.String("job", spec.Name), zap.Error(err))
+		}
+		runtime.Logger.Info("Job scheduled", zap.String("job", spec.Name), zap.String("workflowID", spec.WorkflowID()))
+	}
+}
+
+// startOrUpdate starts the Cron workflow of a job, or updates its schedule when it is running already.
+// The workflow id stays the same when the workflow continues as new.
+func startOrUpdate(workflowClient client.Client, workflowID string, schedule *workflow.CronSchedule) error {
+	workflowOptions := client.StartWorkflowOptions{
+		ID:                              workflowID,
 		TaskList:                        "cron-decider",
 		ExecutionStartToCloseTimeout:    24 * time.Hour,
 		DecisionTaskStartToCloseTimeout: 20 * time.Minute,
 	}
 
-	schedule := &workflow.CronSchedule{
-		Count:      5,
-		Frequency:  2 * time.Minute,
-		Hostgroups: []string{"hostgroup-1", "hostgroup-2"},
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	_, err := workflowClient.StartWorkflow(ctx, workflowOptions, workflow.Cron, schedule, (*workflow.CronState)(nil))
+	if _, ok := err.(*s.WorkflowExecutionAlreadyStartedError); ok {
+		return workflowClient.SignalWorkflow(ctx, workflowID, "", workflow.SignalUpdate, &workflow.ScheduleUpdate{Schedule: schedule})
 	}
-
-	runtime.StartWorkflow(workflowOptions, workflow.Cron, schedule)
+	return err
 }
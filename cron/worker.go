@@ -1,19 +1,44 @@
 package main
 
 import (
+	"flag"
+	"strings"
+
+	"github.com/venkat1109/cadence-codelab/common"
 	_ "github.com/venkat1109/cadence-codelab/cron/activity"
+	"github.com/venkat1109/cadence-codelab/cron/jobs"
 	_ "github.com/venkat1109/cadence-codelab/cron/workflow"
-	//"github.com/venkat1109/cadence-codelab/common"
-	//"go.uber.org/cadence"
-	"fmt"
+	"go.uber.org/cadence/worker"
+	"go.uber.org/zap"
 )
 
-const (
-	decisionTaskList   = "cron-decider"
-	hostgroup1TaskList = "hostgroup-1"
-	hostgroup2TaskList = "hostgroup-2"
-)
+const decisionTaskList = "cron-decider"
 
 func main() {
-	fmt.Println("Not implemented")
+	jobsFile := flag.String("jobs", "cron/jobs.yaml", "jobs file")
+	hostgroups := flag.String("hostgroups", "", "comma separated hostgroups whose jobs run on this host, default is all the hostgroups of the jobs file")
+	flag.Parse()
+
+	runtime := common.NewRuntime()
+
+	file, err := jobs.Load(*jobsFile)
+	if err != nil {
+		runtime.Logger.Fatal("Failed to load jobs", zap.Error(err))
+	}
+	taskLists := file.Hostgroups()
+	if len(*hostgroups) > 0 {
+		taskLists = strings.Split(*hostgroups, ",")
+	}
+
+	options := worker.Options{
+		MetricsScope: runtime.Scope,
+		Logger:       runtime.Logger,
+	}
+	runtime.StartWorkers(runtime.Config.DomainName, decisionTaskList, options)
+	// each hostgroup has its own task list, so that a job runs on the hosts of its hostgroup
+	for _, taskList := range taskLists {
+		runtime.StartWorkers(runtime.Config.DomainName, strings.TrimSpace(taskList), options)
+	}
+	runtime.Logger.Info("Cron worker started", zap.Int("jobs", len(file.Jobs)), zap.Strings("hostgroups", taskLists))
+	select {}
 }
@@ -0,0 +1,116 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/cron/notify"
+	sdk "go.uber.org/cadence"
+	cadence "go.uber.org/cadence/workflow"
+	"go.uber.org/zap"
+)
+
+// defaultAlertAfterFailures is the number of failed runs in a row raising a
+// consecutive failures alert
+const defaultAlertAfterFailures = 3
+
+// validateNotifiers checks the alerting settings of the schedule
+func (s *CronSchedule) validateNotifiers() error {
+	if s.AlertAfterFailures < 0 {
+		return errors.New("alert after failures must not be negative")
+	}
+	for i := range s.Notifiers {
+		if err := s.Notifiers[i].Validate(); err != nil {
+			return fmt.Errorf("notifier %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (s *CronSchedule) alertAfterFailures() int {
+	if s.AlertAfterFailures > 0 {
+		return s.AlertAfterFailures
+	}
+	return defaultAlertAfterFailures
+}
+
+// alert notifies the failed and timed out jobs of a finished run. Live runs, as
+// opposed to backfills, also raise consecutive failures and recovery alerts.
+func (s *scheduler) alert(result *RunResult, live bool) {
+	for _, job := range result.Jobs {
+		switch job.Status {
+		case JobFailed:
+			s.notify(s.newEvent(notify.EventFailure, result, &job))
+		case JobTimedOut:
+			s.notify(s.newEvent(notify.EventTimeout, result, &job))
+		}
+	}
+	if !live {
+		return
+	}
+
+	if len(result.Failed) > 0 {
+		s.state.ConsecutiveFailures++
+		if s.state.ConsecutiveFailures == s.schedule.alertAfterFailures() {
+			event := s.newEvent(notify.EventConsecutiveFailures, result, nil)
+			event.ConsecutiveFailures = s.state.ConsecutiveFailures
+			s.notify(event)
+		}
+	} else if len(result.Succeeded) > 0 {
+		// a run aborted before any job ran neither fails nor recovers
+		if s.state.ConsecutiveFailures > 0 {
+			s.notify(s.newEvent(notify.EventRecovery, result, nil))
+		}
+		s.state.ConsecutiveFailures = 0
+	}
+}
+
+func (s *scheduler) newEvent(eventType notify.EventType, result *RunResult, job *JobOutcome) notify.Event {
+	event := notify.Event{
+		Type:       eventType,
+		WorkflowID: cadence.GetInfo(s.ctx).WorkflowExecution.ID,
+		Job:        s.schedule.Job.Name,
+		FireTime:   result.FireTime,
+	}
+	if job != nil {
+		event.Hostgroup = job.Hostgroup
+		event.Error = job.Error
+		event.ExitCode = job.ExitCode
+		event.OutputDigest = job.OutputDigest
+	}
+	return event
+}
+
+// notify sends the event to the notifiers of the schedule that want it, each
+// in its own activity so that a failing notifier does not hold back the others
+func (s *scheduler) notify(event notify.Event) {
+	logger := cadence.GetLogger(s.ctx)
+	ctx := cadence.WithActivityOptions(s.ctx, cadence.ActivityOptions{
+		ScheduleToStartTimeout: time.Minute,
+		StartToCloseTimeout:    time.Minute,
+		RetryPolicy: &sdk.RetryPolicy{
+			InitialInterval:    10 * time.Second,
+			BackoffCoefficient: 2,
+			MaximumInterval:    time.Minute,
+			MaximumAttempts:    3,
+		},
+	})
+	for _, config := range s.schedule.Notifiers {
+		if !config.Wants(event.Type) {
+			continue
+		}
+		s.notifying++
+		s.estimatedEvents += estimatedEventsPerJob
+
+		future := cadence.ExecuteActivity(ctx, notify.Notify, config, event)
+		notifierType := config.Type
+		cadence.Go(s.ctx, func(ctx cadence.Context) {
+			if err := future.Get(ctx, nil); err != nil {
+				logger.Warn("Notification failed", zap.String("type", notifierType),
+					zap.String("event", string(event.Type)), zap.Error(err))
+			}
+			s.notifyDoneC.Send(ctx, true)
+		})
+	}
+}
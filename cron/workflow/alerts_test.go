@@ -0,0 +1,106 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/venkat1109/cadence-codelab/cron/notify"
+	"go.uber.org/cadence/testsuite"
+)
+
+// onNotify mocks the Notify activity and returns the events it was called with
+func onNotify(env *testsuite.TestWorkflowEnvironment) func() []notify.Event {
+	var lock sync.Mutex
+	var events []notify.Event
+	env.OnActivity(notify.Notify, mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, config notify.Config, event notify.Event) error {
+			lock.Lock()
+			defer lock.Unlock()
+			events = append(events, event)
+			return nil
+		})
+	return func() []notify.Event {
+		lock.Lock()
+		defer lock.Unlock()
+		return events
+	}
+}
+
+func eventTypes(events []notify.Event) []notify.EventType {
+	var types []notify.EventType
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func TestAlerts(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []notify.EventType // events the notifier subscribes to
+		want   []notify.EventType
+	}{
+		{
+			name: "all events",
+			want: []notify.EventType{notify.EventFailure, notify.EventFailure, notify.EventConsecutiveFailures, notify.EventRecovery},
+		},
+		{
+			name:   "subscribed events",
+			events: []notify.EventType{notify.EventConsecutiveFailures, notify.EventRecovery},
+			want:   []notify.EventType{notify.EventConsecutiveFailures, notify.EventRecovery},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv()
+			onJobs(env, 0, errors.New("first"), errors.New("second"))
+			notified := onNotify(env)
+			schedule := newTestSchedule("hostgroup-1")
+			schedule.Count = 3
+			schedule.AlertAfterFailures = 2
+			schedule.Notifiers = []notify.Config{{Type: notify.TypeFile, Path: "/tmp/cron-alerts.json", Events: tt.events}}
+
+			state := runCron(t, env, schedule, nil).State
+			assert.Zero(t, state.ConsecutiveFailures)
+			events := notified()
+			// notifications run concurrently, only the set of events is deterministic
+			assert.ElementsMatch(t, tt.want, eventTypes(events))
+			for _, event := range events {
+				assert.Equal(t, "test", event.Job)
+				assert.NotEmpty(t, event.WorkflowID)
+				if event.Type == notify.EventFailure {
+					assert.Equal(t, "hostgroup-1", event.Hostgroup)
+					assert.NotEmpty(t, event.Error)
+				}
+				if event.Type == notify.EventConsecutiveFailures {
+					assert.Equal(t, 2, event.ConsecutiveFailures)
+				}
+			}
+		})
+	}
+}
+
+func TestBackfillFailuresDoNotCountAsConsecutive(t *testing.T) {
+	env := newTestEnv()
+	onJobs(env, 0, errors.New("first"), errors.New("second"))
+	notified := onNotify(env)
+	schedule := newTestSchedule("hostgroup-1")
+	schedule.Expression = "0 * * * *"
+	schedule.AlertAfterFailures = 2
+	schedule.Notifiers = []notify.Config{{Type: notify.TypeFile, Path: "/tmp/cron-alerts.json"}}
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(SignalBackfill, backfillRequest(7, 8))
+	}, time.Second)
+
+	state := runCron(t, env, schedule, nil).State
+	assert.Zero(t, state.ConsecutiveFailures)
+	require.NotNil(t, state.LastBackfill)
+	assert.Equal(t, 2, state.LastBackfill.Failed)
+	assert.ElementsMatch(t, []notify.EventType{notify.EventFailure, notify.EventFailure}, eventTypes(notified()))
+}
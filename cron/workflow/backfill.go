@@ -0,0 +1,245 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	cadence "go.uber.org/cadence/workflow"
+	"go.uber.org/zap"
+)
+
+// SignalBackfill runs the fire times of the schedule within a time range, typically
+// ones missed while the workflow was down or paused. The payload is a BackfillRequest.
+// Backfills run alongside the live runs of the schedule, the overlap policy of the
+// schedule and the one of the request do not apply between them.
+const SignalBackfill = "backfill"
+
+const (
+	// maxBackfillTicks is the number of fire times a single backfill may run
+	maxBackfillTicks = 1000
+	// maxQueuedBackfills is the number of backfills buffered behind the one in progress
+	maxQueuedBackfills = 10
+)
+
+type (
+	// BackfillRequest is the payload of SignalBackfill
+	BackfillRequest struct {
+		Start       time.Time     // first fire time of the range, inclusive
+		End         time.Time     // last fire time of the range, inclusive
+		Parallelism int           // fire times run at once, 1 by default
+		Overlap     OverlapPolicy // what happens when another backfill is in progress already, skip by default
+	}
+
+	// BackfillState is the progress of a backfill
+	BackfillState struct {
+		Request   BackfillRequest
+		Total     int         // fire times in the range
+		Pending   []time.Time // fire times not started yet, or interrupted by continuing as new
+		Running   int         // fire times running
+		Succeeded int         // fire times whose jobs all succeeded
+		Failed    int         // fire times with failed or aborted jobs
+		Canceled  bool        // whether the backfill was canceled by a later one
+	}
+)
+
+func (r *BackfillRequest) validate() error {
+	if r.Start.IsZero() || r.End.IsZero() {
+		return errors.New("backfill start and end are required")
+	}
+	if r.End.Before(r.Start) {
+		return errors.New("backfill end must not be before start")
+	}
+	if r.Parallelism < 0 {
+		return errors.New("backfill parallelism must not be negative")
+	}
+	switch r.Overlap {
+	case "", OverlapSkip, OverlapBufferOne, OverlapBufferAll, OverlapCancelPrevious, OverlapTerminatePrevious:
+		return nil
+	}
+	return fmt.Errorf("invalid backfill overlap policy %s", r.Overlap)
+}
+
+func (r *BackfillRequest) parallelism() int {
+	if r.Parallelism > 0 {
+		return r.Parallelism
+	}
+	return 1
+}
+
+// fireTimesBetween returns the fire times of the schedule within [start, end]
+func (s *CronSchedule) fireTimesBetween(start, end time.Time) ([]time.Time, error) {
+	var fireTimes []time.Time
+	after := start.Add(-time.Nanosecond)
+	for {
+		next, err := s.nextFireTime(after)
+		if err != nil {
+			return nil, err
+		}
+		if next.IsZero() || next.After(end) {
+			return fireTimes, nil
+		}
+		if len(fireTimes) == maxBackfillTicks {
+			return nil, fmt.Errorf("backfill range has more than %d fire times", maxBackfillTicks)
+		}
+		fireTimes = append(fireTimes, next)
+		after = next
+	}
+}
+
+// onBackfill starts the requested backfill, or applies its overlap policy when
+// a backfill is in progress already
+func (s *scheduler) onBackfill(request BackfillRequest) {
+	logger := cadence.GetLogger(s.ctx)
+	if err := request.validate(); err != nil {
+		s.rejectBackfill(request, err)
+		return
+	}
+	if s.state.Backfill == nil {
+		s.startBackfill(request)
+		return
+	}
+
+	switch request.Overlap {
+	case OverlapBufferOne:
+		if len(s.state.QueuedBackfills) > 0 {
+			s.rejectBackfill(request, errors.New("a backfill is buffered already"))
+			return
+		}
+		s.state.QueuedBackfills = append(s.state.QueuedBackfills, request)
+	case OverlapBufferAll:
+		if len(s.state.QueuedBackfills) == maxQueuedBackfills {
+			s.rejectBackfill(request, fmt.Errorf("%d backfills are buffered already", maxQueuedBackfills))
+			return
+		}
+		s.state.QueuedBackfills = append(s.state.QueuedBackfills, request)
+	case OverlapCancelPrevious:
+		// the request starts once the jobs of the canceled backfill stopped
+		logger.Info("Canceling the previous backfill")
+		s.cancelBackfill()
+		s.state.QueuedBackfills = []BackfillRequest{request}
+	case OverlapTerminatePrevious:
+		logger.Info("Terminating the previous backfill")
+		s.cancelBackfill()
+		s.endBackfill()
+		s.state.QueuedBackfills = nil
+		s.startBackfill(request)
+	default:
+		s.rejectBackfill(request, errors.New("a backfill is in progress"))
+	}
+}
+
+func (s *scheduler) rejectBackfill(request BackfillRequest, err error) {
+	cadence.GetLogger(s.ctx).Warn("Rejected backfill", zap.Time("start", request.Start), zap.Time("end", request.End), zap.Error(err))
+	s.state.BackfillError = err.Error()
+}
+
+func (s *scheduler) startBackfill(request BackfillRequest) {
+	fireTimes, err := s.schedule.fireTimesBetween(request.Start, request.End)
+	if err != nil {
+		s.rejectBackfill(request, err)
+		return
+	}
+	cadence.GetLogger(s.ctx).Info("Backfill started", zap.Time("start", request.Start), zap.Time("end", request.End),
+		zap.Int("fireTimes", len(fireTimes)), zap.Int("parallelism", request.parallelism()))
+	s.state.BackfillError = ""
+	s.state.Backfill = &BackfillState{Request: request, Total: len(fireTimes), Pending: fireTimes}
+}
+
+// runBackfill starts the pending fire times of the backfill in progress, up to its parallelism.
+// Once a backfill ended, the next buffered one is started in the same pass.
+func (s *scheduler) runBackfill() {
+	if s.continuing {
+		return
+	}
+	for {
+		for s.state.Backfill == nil && len(s.state.QueuedBackfills) > 0 {
+			request := s.state.QueuedBackfills[0]
+			s.state.QueuedBackfills = s.state.QueuedBackfills[1:]
+			s.startBackfill(request)
+		}
+
+		b := s.state.Backfill
+		if b == nil {
+			return
+		}
+		if len(b.Pending) > 0 {
+			if s.backfillWait != nil {
+				return
+			}
+			// backfills honor the calendar as well, they resume once the blackout is over
+			now := cadence.Now(s.ctx)
+			if until, blackedOut, err := s.schedule.blockedUntil(now); err == nil && blackedOut {
+				cadence.GetLogger(s.ctx).Info("Backfill waiting for the end of the blackout", zap.Time("until", until))
+				s.backfillWait = cadence.NewTimer(s.ctx, until.Sub(now))
+				s.estimatedEvents += estimatedEventsPerTick
+				return
+			}
+		}
+		if s.backfillCtx == nil {
+			// a backfill carried over from the previous run of the workflow gets a new context as well
+			s.backfillID++
+			s.backfillCtx, s.backfillCancel = cadence.WithCancel(s.ctx)
+		}
+		for b.Running < b.Request.parallelism() && len(b.Pending) > 0 {
+			fireTime := b.Pending[0]
+			b.Pending = b.Pending[1:]
+			b.Running++
+			s.outstanding++
+			s.estimatedEvents += estimatedEventsPerJob * len(s.schedule.Hostgroups)
+
+			backfillID, backfillCtx, schedule := s.backfillID, s.backfillCtx, *s.schedule
+			cadence.Go(s.ctx, func(ctx cadence.Context) {
+				result := runJobs(backfillCtx, &schedule, fireTime)
+				result.Backfill = true
+				s.runDoneC.Send(ctx, runOutcome{backfillID: backfillID, result: result})
+			})
+		}
+		if b.Running > 0 {
+			return
+		}
+		s.endBackfill()
+	}
+}
+
+// cancelBackfill cancels the jobs of the backfill in progress, the backfill ends
+// once they stopped
+func (s *scheduler) cancelBackfill() {
+	if s.backfillCancel != nil {
+		s.backfillCancel()
+	}
+	s.state.Backfill.Pending = nil
+	s.state.Backfill.Canceled = true
+}
+
+// endBackfill records the backfill in progress as the last one, outcomes of its
+// jobs still running are ignored
+func (s *scheduler) endBackfill() {
+	b := s.state.Backfill
+	cadence.GetLogger(s.ctx).Info("Backfill ended", zap.Int("succeeded", b.Succeeded), zap.Int("failed", b.Failed),
+		zap.Bool("canceled", b.Canceled))
+	s.state.LastBackfill = b
+	s.state.Backfill = nil
+	s.backfillCtx = nil
+	s.backfillCancel = nil
+}
+
+func (s *scheduler) onBackfillRunDone(outcome runOutcome) {
+	if outcome.backfillID != s.backfillID || s.state.Backfill == nil {
+		return
+	}
+	b := s.state.Backfill
+	b.Running--
+	if s.deadlineHit && (len(outcome.result.Failed) > 0 || len(outcome.result.Aborted) > 0) {
+		// interrupted by the deadline, run again by the next run of the workflow
+		b.Pending = append([]time.Time{outcome.result.FireTime}, b.Pending...)
+		return
+	}
+	if len(outcome.result.Failed) == 0 && len(outcome.result.Aborted) == 0 {
+		b.Succeeded++
+	} else {
+		b.Failed++
+	}
+	s.recordRun(outcome.result)
+	s.alert(outcome.result, false)
+}
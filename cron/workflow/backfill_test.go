@@ -0,0 +1,213 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFireTimesBetween(t *testing.T) {
+	day := func(hour int) time.Time {
+		return time.Date(2026, 3, 2, hour, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		schedule CronSchedule
+		start    time.Time
+		end      time.Time
+		want     []time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "bounds are inclusive",
+			schedule: CronSchedule{Expression: "0 * * * *"},
+			start:    day(7),
+			end:      day(9),
+			want:     []time.Time{day(7), day(8), day(9)},
+		},
+		{
+			name:     "no fire time in range",
+			schedule: CronSchedule{Expression: "0 * * * *"},
+			start:    day(7).Add(time.Minute),
+			end:      day(7).Add(time.Hour - time.Minute),
+		},
+		{
+			name:     "single instant",
+			schedule: CronSchedule{Expression: "0 * * * *"},
+			start:    day(7),
+			end:      day(7),
+			want:     []time.Time{day(7)},
+		},
+		{
+			name:     "stops at the end time of the schedule",
+			schedule: CronSchedule{Expression: "0 * * * *", EndTime: day(8)},
+			start:    day(7),
+			end:      day(12),
+			want:     []time.Time{day(7), day(8)},
+		},
+		{
+			name:     "too many fire times",
+			schedule: CronSchedule{Expression: "* * * * *"},
+			start:    day(0),
+			end:      day(23),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fireTimes, err := tt.schedule.fireTimesBetween(tt.start, tt.end)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, fireTimes, len(tt.want))
+			for i, want := range tt.want {
+				assert.True(t, want.Equal(fireTimes[i]), "expected %v, got %v", want, fireTimes[i])
+			}
+		})
+	}
+}
+
+// backfillRequest returns a request for the hourly fire times of the test day between the hours
+func backfillRequest(startHour, endHour int) BackfillRequest {
+	return BackfillRequest{
+		Start: time.Date(2026, 3, 2, startHour, 0, 0, 0, time.UTC),
+		End:   time.Date(2026, 3, 2, endHour, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	env := newTestEnv()
+	onJobs(env, 0)
+	schedule := newTestSchedule("hostgroup-1")
+	schedule.Expression = "0 * * * *"
+	request := backfillRequest(7, 9)
+	request.Parallelism = 2
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(SignalBackfill, request)
+	}, time.Second)
+
+	state := runCron(t, env, schedule, nil).State
+	assert.Nil(t, state.Backfill)
+	require.NotNil(t, state.LastBackfill)
+	assert.Equal(t, 3, state.LastBackfill.Total)
+	assert.Equal(t, 3, state.LastBackfill.Succeeded)
+	assert.Zero(t, state.LastBackfill.Failed)
+	assert.Empty(t, state.BackfillError)
+	// backfilled runs do not count as ticks of the schedule
+	assert.Equal(t, 1, state.Ticks)
+	assert.Equal(t, 1, state.Runs)
+
+	require.Len(t, state.RecentRuns, 4)
+	var backfilled []time.Time
+	for _, run := range state.RecentRuns[:3] {
+		assert.True(t, run.Backfill)
+		backfilled = append(backfilled, run.FireTime)
+	}
+	assert.False(t, state.RecentRuns[3].Backfill)
+	assert.True(t, time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC).Equal(state.RecentRuns[3].FireTime))
+	for _, hour := range []int{7, 8, 9} {
+		want := time.Date(2026, 3, 2, hour, 0, 0, 0, time.UTC)
+		found := false
+		for _, fireTime := range backfilled {
+			found = found || want.Equal(fireTime)
+		}
+		assert.True(t, found, "%v was not backfilled", want)
+	}
+}
+
+func TestBackfillInProgress(t *testing.T) {
+	tests := []struct {
+		overlap   OverlapPolicy
+		rejected  bool
+		lastTotal int
+	}{
+		{overlap: OverlapSkip, rejected: true, lastTotal: 3},
+		{overlap: OverlapBufferOne, lastTotal: 1},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.overlap), func(t *testing.T) {
+			env := newTestEnv()
+			onJobs(env, 30*time.Second)
+			schedule := newTestSchedule("hostgroup-1")
+			schedule.Expression = "0 * * * *"
+			env.RegisterDelayedCallback(func() {
+				env.SignalWorkflow(SignalBackfill, backfillRequest(7, 9))
+			}, time.Second)
+			second := backfillRequest(5, 5)
+			second.Overlap = tt.overlap
+			env.RegisterDelayedCallback(func() {
+				env.SignalWorkflow(SignalBackfill, second)
+			}, 2*time.Second)
+
+			state := runCron(t, env, schedule, nil).State
+			assert.Equal(t, tt.rejected, len(state.BackfillError) > 0)
+			assert.Empty(t, state.QueuedBackfills)
+			require.NotNil(t, state.LastBackfill)
+			assert.Equal(t, tt.lastTotal, state.LastBackfill.Total)
+			assert.Equal(t, tt.lastTotal, state.LastBackfill.Succeeded)
+		})
+	}
+}
+
+func TestBufferedBackfillOutlivesSchedule(t *testing.T) {
+	env := newTestEnv()
+	onJobs(env, 30*time.Second)
+	// the only live run fires at 10:31, while the first backfill is going
+	schedule := newTestSchedule("hostgroup-1")
+	schedule.Expression = "* * * * *"
+	first := BackfillRequest{
+		Start: time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC),
+		End:   time.Date(2026, 3, 2, 7, 1, 0, 0, time.UTC),
+	}
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(SignalBackfill, first)
+	}, time.Second)
+	second := backfillRequest(5, 5)
+	second.Overlap = OverlapBufferOne
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(SignalBackfill, second)
+	}, 2*time.Second)
+
+	state := runCron(t, env, schedule, nil).State
+	assert.Equal(t, 1, state.Ticks)
+	assert.Empty(t, state.QueuedBackfills)
+	require.NotNil(t, state.LastBackfill)
+	assert.True(t, second.Start.Equal(state.LastBackfill.Request.Start))
+	assert.Equal(t, 1, state.LastBackfill.Succeeded)
+
+	require.NotEmpty(t, state.RecentRuns)
+	run := state.RecentRuns[len(state.RecentRuns)-1]
+	assert.True(t, second.Start.Equal(run.FireTime))
+	require.Len(t, run.Jobs, 1)
+	// the buffered backfill starts as soon as the first one ended, not at the next event
+	assert.True(t, testStart.Add(61*time.Second).Equal(run.Jobs[0].StartTime), "started at %v", run.Jobs[0].StartTime)
+}
+
+func TestBackfillCarriedOverAtDeadline(t *testing.T) {
+	env := newTestEnv()
+	// the workflow continues as new a minute in, half the timeout
+	env.SetWorkflowTimeout(2 * time.Minute)
+	onJobs(env, 30*time.Second)
+	schedule := newTestSchedule("hostgroup-1")
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(SignalBackfill, backfillRequest(7, 9))
+	}, time.Second)
+
+	env.ExecuteWorkflow(Cron, schedule, nil)
+	requireContinuedAsNew(t, env)
+	state := queryStatus(t, env).State
+	b := state.Backfill
+	require.NotNil(t, b)
+	// 07:00 ran, 08:00 was interrupted by the deadline and runs again with 09:00
+	assert.Equal(t, 1, b.Succeeded)
+	assert.Zero(t, b.Failed)
+	assert.Zero(t, b.Running)
+	require.Len(t, b.Pending, 2)
+	assert.True(t, backfillRequest(8, 8).Start.Equal(b.Pending[0]))
+	assert.True(t, backfillRequest(9, 9).Start.Equal(b.Pending[1]))
+}
@@ -0,0 +1,151 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// BlackoutPolicy is what happens to a fire time falling in a blackout
+type BlackoutPolicy string
+
+const (
+	// BlackoutSkip skips the fire time, this is the default
+	BlackoutSkip BlackoutPolicy = "skip"
+	// BlackoutDefer runs the first fire time of the blackout once it is over, the later
+	// fire times of the same blackout are merged into that run as skip does
+	BlackoutDefer BlackoutPolicy = "defer"
+)
+
+const (
+	holidayLayout = "2006-01-02"
+	clockLayout   = "15:04"
+	// maxBlackoutDays bounds the search for the end of a blackout
+	maxBlackoutDays = 366
+)
+
+type (
+	// Calendar restricts when the runs of a schedule may start. Times and dates are
+	// evaluated in the time zone of the schedule.
+	Calendar struct {
+		Blackouts    []BlackoutWindow // daily windows in which no run starts
+		Holidays     []string         // dates, formatted as 2006-01-02, on which no run starts
+		BusinessDays bool             // whether runs start from Monday to Friday only
+		Policy       BlackoutPolicy   // what happens to the fire times of a blackout, skip by default
+	}
+
+	// BlackoutWindow is a daily window, formatted as 15:04. A window ending before
+	// it starts spans midnight.
+	BlackoutWindow struct {
+		Start string
+		End   string
+	}
+)
+
+func (c *Calendar) validate() error {
+	switch c.Policy {
+	case "", BlackoutSkip, BlackoutDefer:
+	default:
+		return fmt.Errorf("invalid blackout policy %s, must be %s or %s", c.Policy, BlackoutSkip, BlackoutDefer)
+	}
+	for _, w := range c.Blackouts {
+		start, end, err := w.parse()
+		if err != nil {
+			return err
+		}
+		if start == end {
+			return fmt.Errorf("blackout window %s-%s is empty", w.Start, w.End)
+		}
+	}
+	for _, day := range c.Holidays {
+		if _, err := time.Parse(holidayLayout, day); err != nil {
+			return fmt.Errorf("invalid holiday %s, expected %s", day, holidayLayout)
+		}
+	}
+	return nil
+}
+
+// parse returns the start and end of the window as offsets from midnight
+func (w *BlackoutWindow) parse() (time.Duration, time.Duration, error) {
+	start, err := time.Parse(clockLayout, w.Start)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid blackout start %s, expected %s", w.Start, clockLayout)
+	}
+	end, err := time.Parse(clockLayout, w.End)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid blackout end %s, expected %s", w.End, clockLayout)
+	}
+	return sinceMidnight(start), sinceMidnight(end), nil
+}
+
+// blockedUntil returns whether the calendar forbids runs at t and, if so, the first
+// time after t at which they are allowed again. It only depends on its arguments so
+// that it can be called from workflow code.
+func (s *CronSchedule) blockedUntil(t time.Time) (time.Time, bool, error) {
+	c := s.Calendar
+	if c == nil {
+		return t, false, nil
+	}
+	loc, err := s.location()
+	if err != nil {
+		return t, false, err
+	}
+	holidays := make(map[string]bool, len(c.Holidays))
+	for _, day := range c.Holidays {
+		holidays[day] = true
+	}
+
+	allowed := t.In(loc)
+	for i := 0; i < maxBlackoutDays*(len(c.Blackouts)+1); i++ {
+		next, blocked, err := c.blockedAt(allowed, holidays)
+		if err != nil {
+			return t, false, err
+		}
+		if !blocked {
+			return allowed, !allowed.Equal(t), nil
+		}
+		allowed = next
+	}
+	return t, false, errors.New("calendar never allows a run")
+}
+
+// blockedAt returns whether a blackout, holiday or week end covers t, and when it ends
+func (c *Calendar) blockedAt(t time.Time, holidays map[string]bool) (time.Time, bool, error) {
+	nextMidnight := atClock(t.AddDate(0, 0, 1), 0)
+	if holidays[t.Format(holidayLayout)] {
+		return nextMidnight, true, nil
+	}
+	if c.BusinessDays && (t.Weekday() == time.Saturday || t.Weekday() == time.Sunday) {
+		return nextMidnight, true, nil
+	}
+
+	offset := sinceMidnight(t)
+	for _, w := range c.Blackouts {
+		start, end, err := w.parse()
+		if err != nil {
+			return t, false, err
+		}
+		switch {
+		case start < end && offset >= start && offset < end:
+			return atClock(t, end), true, nil
+		case start > end && offset >= start:
+			// spans midnight, blocked until the end time of the next day
+			return atClock(t.AddDate(0, 0, 1), end), true, nil
+		case start > end && offset < end:
+			return atClock(t, end), true, nil
+		}
+	}
+	return t, false, nil
+}
+
+// sinceMidnight returns the wall clock time of t as an offset from midnight
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+}
+
+// atClock returns the time of the day of t at the given wall clock offset from midnight,
+// which differs from midnight plus the offset on daylight saving days
+func atClock(t time.Time, clock time.Duration) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), int(clock/time.Hour), int(clock%time.Hour/time.Minute), 0, 0, t.Location())
+}
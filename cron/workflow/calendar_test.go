@@ -0,0 +1,189 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockedAt(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, time.UTC)
+	}
+	overnight := []BlackoutWindow{{Start: "22:00", End: "06:00"}}
+
+	tests := []struct {
+		name     string
+		calendar Calendar
+		t        time.Time // 2026-03-02 is a Monday
+		blocked  bool
+		until    time.Time
+	}{
+		{name: "before the window", calendar: Calendar{Blackouts: overnight}, t: at(2, 21, 59)},
+		{name: "window end is allowed", calendar: Calendar{Blackouts: overnight}, t: at(2, 6, 0)},
+		{name: "before midnight", calendar: Calendar{Blackouts: overnight}, t: at(2, 23, 0), blocked: true, until: at(3, 6, 0)},
+		{name: "after midnight", calendar: Calendar{Blackouts: overnight}, t: at(2, 5, 0), blocked: true, until: at(2, 6, 0)},
+		{
+			name:     "within the day",
+			calendar: Calendar{Blackouts: []BlackoutWindow{{Start: "12:00", End: "13:00"}}},
+			t:        at(2, 12, 30),
+			blocked:  true,
+			until:    at(2, 13, 0),
+		},
+		{name: "holiday", calendar: Calendar{Holidays: []string{"2026-03-02"}}, t: at(2, 10, 0), blocked: true, until: at(3, 0, 0)},
+		{name: "saturday", calendar: Calendar{BusinessDays: true}, t: at(7, 10, 0), blocked: true, until: at(8, 0, 0)},
+		{name: "weekday", calendar: Calendar{BusinessDays: true}, t: at(6, 10, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			holidays := make(map[string]bool)
+			for _, day := range tt.calendar.Holidays {
+				holidays[day] = true
+			}
+			until, blocked, err := tt.calendar.blockedAt(tt.t, holidays)
+			require.NoError(t, err)
+			assert.Equal(t, tt.blocked, blocked)
+			if tt.blocked {
+				assert.True(t, tt.until.Equal(until), "expected %v, got %v", tt.until, until)
+			}
+		})
+	}
+}
+
+func TestBlockedUntil(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		calendar Calendar
+		timeZone string
+		t        time.Time
+		blocked  bool
+		until    time.Time
+	}{
+		{
+			name:     "week end rolls to monday",
+			calendar: Calendar{BusinessDays: true},
+			t:        time.Date(2026, 3, 7, 10, 0, 0, 0, time.UTC),
+			blocked:  true,
+			until:    time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "friday holiday rolls to monday",
+			calendar: Calendar{BusinessDays: true, Holidays: []string{"2026-03-06"}},
+			t:        time.Date(2026, 3, 6, 10, 0, 0, 0, time.UTC),
+			blocked:  true,
+			until:    time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "week end then morning window",
+			calendar: Calendar{BusinessDays: true, Blackouts: []BlackoutWindow{{Start: "00:00", End: "06:00"}}},
+			t:        time.Date(2026, 3, 7, 10, 0, 0, 0, time.UTC),
+			blocked:  true,
+			until:    time.Date(2026, 3, 9, 6, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "holiday in the schedule time zone",
+			calendar: Calendar{Holidays: []string{"2026-12-25"}},
+			timeZone: "America/New_York",
+			// still December 24th in New York
+			t: time.Date(2026, 12, 25, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "window over the daylight saving change",
+			calendar: Calendar{Blackouts: []BlackoutWindow{{Start: "00:00", End: "06:00"}}},
+			timeZone: "America/New_York",
+			// 01:00 EST, an hour before clocks move forward
+			t:       time.Date(2026, 3, 8, 6, 0, 0, 0, time.UTC),
+			blocked: true,
+			until:   time.Date(2026, 3, 8, 6, 0, 0, 0, newYork),
+		},
+		{
+			name:     "not blocked",
+			calendar: Calendar{Blackouts: []BlackoutWindow{{Start: "12:00", End: "13:00"}}},
+			t:        time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := tt.calendar
+			schedule := CronSchedule{Calendar: &calendar, TimeZone: tt.timeZone}
+			until, blocked, err := schedule.blockedUntil(tt.t)
+			require.NoError(t, err)
+			assert.Equal(t, tt.blocked, blocked)
+			want := tt.t
+			if tt.blocked {
+				want = tt.until
+			}
+			assert.True(t, want.Equal(until), "expected %v, got %v", want, until)
+		})
+	}
+}
+
+func TestBlackoutSkip(t *testing.T) {
+	env := newTestEnv()
+	onJobs(env, 0)
+	schedule := newTestSchedule("hostgroup-1")
+	schedule.Expression = "0 * * * *"
+	schedule.Count = 2
+	schedule.Calendar = &Calendar{Blackouts: []BlackoutWindow{{Start: "12:00", End: "14:00"}}}
+
+	state := runCron(t, env, schedule, nil).State
+	assert.Equal(t, 2, state.Ticks)
+	// the 12:00 and 13:00 fire times fall in the same blackout
+	assert.Equal(t, 1, state.BlackedOut)
+	require.Len(t, state.RecentRuns, 2)
+	assert.True(t, time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC).Equal(state.RecentRuns[0].FireTime))
+	assert.True(t, time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC).Equal(state.RecentRuns[1].FireTime))
+}
+
+func TestBlackoutDefer(t *testing.T) {
+	env := newTestEnv()
+	onJobs(env, 0)
+	schedule := newTestSchedule("hostgroup-1")
+	schedule.Expression = "0 * * * *"
+	schedule.Count = 2
+	schedule.Calendar = &Calendar{Blackouts: []BlackoutWindow{{Start: "12:00", End: "14:00"}}, Policy: BlackoutDefer}
+
+	state := runCron(t, env, schedule, nil).State
+	assert.Equal(t, 2, state.Ticks)
+	assert.Equal(t, 1, state.BlackedOut)
+	run := state.LastRun
+	require.NotNil(t, run)
+	// the run keeps its fire time but its jobs start once the blackout is over
+	assert.True(t, time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC).Equal(run.FireTime))
+	require.Len(t, run.Jobs, 1)
+	assert.False(t, run.Jobs[0].StartTime.Before(time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC)))
+}
+
+func TestBlackoutDeferMergesFireTimes(t *testing.T) {
+	env := newTestEnv()
+	onJobs(env, 0)
+	schedule := newTestSchedule("hostgroup-1")
+	schedule.Expression = "0 * * * *"
+	schedule.Count = 3
+	schedule.Calendar = &Calendar{Blackouts: []BlackoutWindow{{Start: "12:00", End: "15:00"}}, Policy: BlackoutDefer}
+
+	state := runCron(t, env, schedule, nil).State
+	assert.Equal(t, 3, state.Ticks)
+	// the 12:00, 13:00 and 14:00 fire times make a single deferred run
+	assert.Equal(t, 1, state.BlackedOut)
+	assert.True(t, time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC).Equal(state.LastFireTime))
+	var fireTimes []time.Time
+	for _, run := range state.RecentRuns {
+		fireTimes = append(fireTimes, run.FireTime)
+	}
+	fireTimes = append(fireTimes, state.SkippedTicks...)
+	require.Len(t, fireTimes, 3)
+	for _, hour := range []int{11, 12, 15} {
+		want := time.Date(2026, 3, 2, hour, 0, 0, 0, time.UTC)
+		found := false
+		for _, fireTime := range fireTimes {
+			found = found || want.Equal(fireTime)
+		}
+		assert.True(t, found, "%v did not fire", want)
+	}
+}
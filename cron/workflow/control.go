@@ -0,0 +1,80 @@
+package workflow
+
+import (
+	"errors"
+	"time"
+)
+
+// Signals accepted by the Cron workflow
+const (
+	// SignalPause stops firing the schedule and running buffered fire times until SignalResume,
+	// the payload is an optional reason
+	SignalPause = "pause"
+	// SignalResume resumes firing the schedule from the current time, fire times missed while
+	// paused are not run
+	SignalResume = "resume"
+	// SignalTrigger runs the jobs now, the overlap policy applies when a run is in progress
+	// but the calendar does not
+	SignalTrigger = "trigger"
+	// SignalUpdate updates the schedule, which then fires from the current time. The payload
+	// is a ScheduleUpdate, an invalid update is rejected and recorded in the CronState.
+	SignalUpdate = "update_schedule"
+)
+
+// QueryStatus is the query returning the CronStatus of the workflow
+const QueryStatus = "status"
+
+// maxRecentRuns is the number of run outcomes kept in the workflow state
+const maxRecentRuns = 20
+
+type (
+	// ScheduleUpdate is the payload of SignalUpdate, only the set fields are updated.
+	// Setting the expression clears the frequency and the other way around.
+	ScheduleUpdate struct {
+		Expression string
+		Frequency  time.Duration
+		TimeZone   string
+		Hostgroups []string
+		Schedule   *CronSchedule // replaces the whole schedule when set, the other fields are ignored
+	}
+
+	// CronStatus is returned by the status query
+	CronStatus struct {
+		Schedule *CronSchedule
+		State    *CronState
+	}
+)
+
+// apply returns the schedule with the update applied, or an error when the
+// updated schedule is not valid
+func (u *ScheduleUpdate) apply(schedule *CronSchedule) (*CronSchedule, error) {
+	if u.Schedule != nil {
+		updated := *u.Schedule
+		if err := updated.Validate(); err != nil {
+			return nil, err
+		}
+		updated.Job = updated.Job.WithDefaults()
+		return &updated, nil
+	}
+	if len(u.Expression) == 0 && u.Frequency == 0 && len(u.TimeZone) == 0 && len(u.Hostgroups) == 0 {
+		return nil, errors.New("empty schedule update")
+	}
+	updated := *schedule
+	if len(u.Expression) > 0 {
+		updated.Expression = u.Expression
+		updated.Frequency = 0
+	} else if u.Frequency != 0 {
+		updated.Frequency = u.Frequency
+		updated.Expression = ""
+	}
+	if len(u.TimeZone) > 0 {
+		updated.TimeZone = u.TimeZone
+	}
+	if len(u.Hostgroups) > 0 {
+		updated.Hostgroups = append([]string(nil), u.Hostgroups...)
+	}
+	if err := updated.Validate(); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
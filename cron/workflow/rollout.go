@@ -0,0 +1,194 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/cron/activity"
+	sdk "go.uber.org/cadence"
+	cadence "go.uber.org/cadence/workflow"
+	"go.uber.org/zap"
+)
+
+// ExecutionMode is how a cron run is rolled out across the hostgroups
+type ExecutionMode string
+
+const (
+	// ModeRolling runs the job on at most MaxInFlight hostgroups at a time, this is the default
+	ModeRolling ExecutionMode = "rolling"
+	// ModeParallel runs the job on all the hostgroups at once
+	ModeParallel ExecutionMode = "parallel"
+	// ModeCanary runs the job on the first hostgroup alone, then rolls out to the
+	// others like ModeRolling once it succeeded
+	ModeCanary ExecutionMode = "canary"
+)
+
+// Job statuses
+const (
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobTimedOut  JobStatus = "timed_out"
+	JobAborted   JobStatus = "aborted"
+)
+
+type (
+	// RunResult is the outcome of one cron run across the hostgroups
+	RunResult struct {
+		FireTime  time.Time
+		Succeeded []string
+		Failed    []string
+		Aborted   []string // hostgroups not run, or canceled once the run was aborted or canceled
+		Backfill  bool     // whether the run was part of a backfill
+		RunID     string   // run of the workflow whose history holds the jobs
+		Jobs      []JobOutcome
+	}
+
+	// JobStatus is how the job of a run ended on one hostgroup
+	JobStatus string
+
+	// JobOutcome is the outcome of the job of a run on one hostgroup
+	JobOutcome struct {
+		Hostgroup    string
+		Status       JobStatus
+		ExitCode     int       // exit status of command jobs
+		OutputDigest string    // digest of the output of command jobs
+		Error        string    // why the job failed, empty when it succeeded
+		StartTime    time.Time // when the job was scheduled
+		EndTime      time.Time // when the job ended
+	}
+)
+
+// validateMode checks the rollout settings of the schedule
+func (s *CronSchedule) validateMode() error {
+	switch s.Mode {
+	case "", ModeRolling, ModeParallel, ModeCanary:
+	default:
+		return fmt.Errorf("invalid mode %s, must be %s, %s or %s", s.Mode, ModeRolling, ModeParallel, ModeCanary)
+	}
+	if s.MaxInFlight < 0 {
+		return errors.New("max in flight must not be negative")
+	}
+	if s.FailureBudget < 0 {
+		return errors.New("failure budget must not be negative")
+	}
+	return nil
+}
+
+// maxInFlight returns the number of hostgroups the job may run on at once
+func (s *CronSchedule) maxInFlight() int {
+	if s.Mode == ModeParallel {
+		return len(s.Hostgroups)
+	}
+	if s.MaxInFlight > 0 {
+		return s.MaxInFlight
+	}
+	return maxJobsPerLoop
+}
+
+// runJobs rolls the cron job out to the hostgroups, each on its own task list. Once
+// more than FailureBudget hostgroups failed, or the canary failed, the jobs still
+// running are canceled and the remaining hostgroups are not run.
+func runJobs(ctx cadence.Context, schedule *CronSchedule, fireTime time.Time) *RunResult {
+	logger := cadence.GetLogger(ctx)
+	result := &RunResult{FireTime: fireTime, RunID: cadence.GetInfo(ctx).WorkflowExecution.RunID}
+
+	jobCtx, cancelJobs := cadence.WithCancel(ctx)
+	defer cancelJobs()
+	jobCtx = cadence.WithActivityOptions(jobCtx, schedule.jobOptions())
+
+	// every job receives the nominal fire time of the run, which differs from the
+	// current time for backfills
+	job := schedule.Job
+	job.ScheduledTime = fireTime
+
+	pending := append([]string(nil), schedule.Hostgroups...)
+	canaryPending := schedule.Mode == ModeCanary
+	aborted := false
+	inFlight := 0
+
+	selector := cadence.NewSelector(ctx)
+	launch := func(hostgroup string) {
+		logger.Info("Starting cron job", zap.String("hostgroup", hostgroup))
+		startTime := cadence.Now(ctx)
+		future := cadence.ExecuteActivity(cadence.WithTaskList(jobCtx, hostgroup), activity.Cron, job)
+		selector.AddFuture(future, func(f cadence.Future) {
+			var progress activity.JobProgress
+			err := f.Get(ctx, &progress)
+			if customErr, ok := err.(*sdk.CustomError); ok && customErr.HasDetails() {
+				customErr.Details(&progress)
+			}
+			outcome := JobOutcome{
+				Hostgroup:    hostgroup,
+				ExitCode:     progress.ExitCode,
+				OutputDigest: progress.OutputDigest,
+				StartTime:    startTime,
+				EndTime:      cadence.Now(ctx),
+			}
+			if err != nil {
+				outcome.Error = err.Error()
+			}
+			switch {
+			case err == nil:
+				outcome.Status = JobSucceeded
+				result.Succeeded = append(result.Succeeded, hostgroup)
+			case isCanceled(err) && (aborted || ctx.Err() != nil):
+				outcome.Status = JobAborted
+				result.Aborted = append(result.Aborted, hostgroup)
+			default:
+				logger.Error("Cron job failed", zap.String("hostgroup", hostgroup), zap.Error(err))
+				outcome.Status = JobFailed
+				if _, ok := err.(*sdk.TimeoutError); ok {
+					outcome.Status = JobTimedOut
+				}
+				result.Failed = append(result.Failed, hostgroup)
+			}
+			result.Jobs = append(result.Jobs, outcome)
+		})
+		inFlight++
+	}
+
+	for {
+		limit := schedule.maxInFlight()
+		if canaryPending {
+			limit = 1
+		}
+		if ctx.Err() != nil && !aborted {
+			// the run was canceled by a later fire time
+			aborted = true
+		}
+		for !aborted && len(pending) > 0 && inFlight < limit {
+			launch(pending[0])
+			pending = pending[1:]
+		}
+		if inFlight == 0 {
+			break
+		}
+
+		selector.Select(ctx)
+		inFlight--
+
+		if canaryPending {
+			canaryPending = false
+			if len(result.Failed) > 0 && !aborted {
+				logger.Warn("Canary hostgroup failed, aborting the run", zap.String("hostgroup", result.Failed[0]))
+				aborted = true
+				cancelJobs()
+			}
+		}
+		if len(result.Failed) > schedule.FailureBudget && !aborted {
+			logger.Warn("Failure budget exceeded, aborting the run",
+				zap.Int("failed", len(result.Failed)), zap.Int("budget", schedule.FailureBudget))
+			aborted = true
+			cancelJobs()
+		}
+	}
+
+	result.Aborted = append(result.Aborted, pending...)
+	return result
+}
+
+func isCanceled(err error) bool {
+	_, ok := err.(*sdk.CanceledError)
+	return ok
+}
@@ -0,0 +1,88 @@
+package workflow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollingRun(t *testing.T) {
+	env := newTestEnv()
+	onJobs(env, 0)
+	schedule := newTestSchedule("hostgroup-1", "hostgroup-2", "hostgroup-3")
+
+	status := runCron(t, env, schedule, nil)
+	run := status.State.LastRun
+	require.NotNil(t, run)
+	assert.True(t, testStart.Add(schedule.Frequency).Equal(run.FireTime))
+	assert.Equal(t, []string{"hostgroup-1", "hostgroup-2", "hostgroup-3"}, run.Succeeded)
+	assert.Empty(t, run.Failed)
+	assert.Empty(t, run.Aborted)
+	assert.NotEmpty(t, run.RunID)
+	require.Len(t, run.Jobs, 3)
+	for _, job := range run.Jobs {
+		assert.Equal(t, JobSucceeded, job.Status)
+		assert.False(t, job.EndTime.Before(job.StartTime))
+	}
+	env.AssertExpectations(t)
+}
+
+func TestCanaryFailureAbortsRun(t *testing.T) {
+	env := newTestEnv()
+	onJobs(env, 0, errors.New("canary failed"))
+	schedule := newTestSchedule("hostgroup-1", "hostgroup-2", "hostgroup-3")
+	schedule.Mode = ModeCanary
+	schedule.FailureBudget = 5
+
+	run := runCron(t, env, schedule, nil).State.LastRun
+	require.NotNil(t, run)
+	assert.Equal(t, []string{"hostgroup-1"}, run.Failed)
+	assert.Equal(t, []string{"hostgroup-2", "hostgroup-3"}, run.Aborted)
+	assert.Empty(t, run.Succeeded)
+	require.Len(t, run.Jobs, 1)
+	assert.Equal(t, JobFailed, run.Jobs[0].Status)
+	assert.Contains(t, run.Jobs[0].Error, "canary failed")
+}
+
+func TestCanarySuccessRollsOut(t *testing.T) {
+	env := newTestEnv()
+	onJobs(env, 0, nil, errors.New("failed"))
+	schedule := newTestSchedule("hostgroup-1", "hostgroup-2", "hostgroup-3")
+	schedule.Mode = ModeCanary
+	schedule.FailureBudget = 1
+
+	run := runCron(t, env, schedule, nil).State.LastRun
+	require.NotNil(t, run)
+	assert.Equal(t, []string{"hostgroup-1", "hostgroup-3"}, run.Succeeded)
+	assert.Equal(t, []string{"hostgroup-2"}, run.Failed)
+	assert.Empty(t, run.Aborted)
+}
+
+func TestFailureBudgetExceededAbortsRun(t *testing.T) {
+	env := newTestEnv()
+	onJobs(env, 0, errors.New("first"), errors.New("second"))
+	schedule := newTestSchedule("hostgroup-1", "hostgroup-2", "hostgroup-3", "hostgroup-4")
+	schedule.FailureBudget = 1
+
+	run := runCron(t, env, schedule, nil).State.LastRun
+	require.NotNil(t, run)
+	assert.Equal(t, []string{"hostgroup-1", "hostgroup-2"}, run.Failed)
+	assert.Equal(t, []string{"hostgroup-3", "hostgroup-4"}, run.Aborted)
+	assert.Empty(t, run.Succeeded)
+}
+
+func TestParallelRunWithinFailureBudget(t *testing.T) {
+	env := newTestEnv()
+	onJobs(env, 0, errors.New("failed"))
+	schedule := newTestSchedule("hostgroup-1", "hostgroup-2", "hostgroup-3")
+	schedule.Mode = ModeParallel
+	schedule.FailureBudget = 1
+
+	run := runCron(t, env, schedule, nil).State.LastRun
+	require.NotNil(t, run)
+	assert.Len(t, run.Failed, 1)
+	assert.Len(t, run.Succeeded, 2)
+	assert.Empty(t, run.Aborted)
+}
@@ -0,0 +1,122 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+// Validate checks the schedule before it is handed to the Cron workflow
+func (s *CronSchedule) Validate() error {
+	if len(s.Hostgroups) == 0 {
+		return errors.New("at least one hostgroup is required")
+	}
+	if s.Count < 0 {
+		return errors.New("count must not be negative")
+	}
+	if s.TicksPerRun < 0 || s.EstimatedEventsPerRun < 0 {
+		return errors.New("ticks and estimated events per run must not be negative")
+	}
+	if s.EstimatedEventsPerRun > maxEstimatedEventsPerRun {
+		return fmt.Errorf("estimated events per run must be at most %d", maxEstimatedEventsPerRun)
+	}
+	if s.Jitter < 0 {
+		return errors.New("jitter must not be negative")
+	}
+	if len(s.Expression) == 0 && s.Frequency <= 0 {
+		return errors.New("either a cron expression or a positive frequency is required")
+	}
+	if !s.StartTime.IsZero() && !s.EndTime.IsZero() && !s.EndTime.After(s.StartTime) {
+		return errors.New("end time must be after start time")
+	}
+	if err := s.validateMode(); err != nil {
+		return err
+	}
+	if err := s.validateOverlap(); err != nil {
+		return err
+	}
+	if err := s.validateNotifiers(); err != nil {
+		return err
+	}
+	if s.Calendar != nil {
+		if err := s.Calendar.validate(); err != nil {
+			return err
+		}
+	}
+	if _, err := s.location(); err != nil {
+		return err
+	}
+	if len(s.Expression) > 0 {
+		if _, err := parseExpression(s.Expression); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextFireTime returns the first fire time strictly after the given time, or the
+// zero time when the schedule has no fire time left before its end time. It only
+// depends on its arguments so that it can be called from workflow code.
+func (s *CronSchedule) nextFireTime(after time.Time) (time.Time, error) {
+	if !s.StartTime.IsZero() && after.Before(s.StartTime) {
+		// the start time itself is a valid fire time
+		after = s.StartTime.Add(-time.Nanosecond)
+	}
+
+	var next time.Time
+	if len(s.Expression) > 0 {
+		schedule, err := parseExpression(s.Expression)
+		if err != nil {
+			return time.Time{}, err
+		}
+		loc, err := s.location()
+		if err != nil {
+			return time.Time{}, err
+		}
+		next = schedule.Next(after.In(loc))
+	} else {
+		next = after.Add(s.Frequency)
+	}
+
+	if next.IsZero() || (!s.EndTime.IsZero() && next.After(s.EndTime)) {
+		return time.Time{}, nil
+	}
+	return next, nil
+}
+
+// location returns the time zone the cron expression is evaluated in
+func (s *CronSchedule) location() (*time.Location, error) {
+	if len(s.TimeZone) == 0 {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %s: %v", s.TimeZone, err)
+	}
+	return loc, nil
+}
+
+// parseExpression parses a standard 5 field cron expression, a 6 field expression
+// starting with seconds, or a descriptor such as @daily
+func parseExpression(expression string) (cron.Schedule, error) {
+	fields := strings.Fields(expression)
+	var schedule cron.Schedule
+	var err error
+	switch {
+	case len(fields) > 0 && strings.HasPrefix(fields[0], "@"):
+		schedule, err = cron.ParseStandard(expression)
+	case len(fields) == 5:
+		schedule, err = cron.ParseStandard(expression)
+	case len(fields) == 6:
+		schedule, err = cron.Parse(expression)
+	default:
+		err = fmt.Errorf("expected 5 or 6 fields, found %d", len(fields))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression '%s': %v", expression, err)
+	}
+	return schedule, nil
+}
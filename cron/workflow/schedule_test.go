@@ -0,0 +1,130 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextFireTime(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		require.NoError(t, err)
+		return parsed
+	}
+
+	tests := []struct {
+		name     string
+		schedule CronSchedule
+		after    string
+		want     string // empty when the schedule has no fire time left
+	}{
+		{
+			name:     "frequency",
+			schedule: CronSchedule{Frequency: 90 * time.Second},
+			after:    "2026-03-02T10:00:00Z",
+			want:     "2026-03-02T10:01:30Z",
+		},
+		{
+			name:     "five fields",
+			schedule: CronSchedule{Expression: "*/15 * * * *"},
+			after:    "2026-03-02T10:00:00Z",
+			want:     "2026-03-02T10:15:00Z",
+		},
+		{
+			name:     "six fields with seconds",
+			schedule: CronSchedule{Expression: "30 0 * * * *"},
+			after:    "2026-03-02T10:00:00Z",
+			want:     "2026-03-02T10:00:30Z",
+		},
+		{
+			name:     "descriptor",
+			schedule: CronSchedule{Expression: "@daily"},
+			after:    "2026-03-02T10:00:00Z",
+			want:     "2026-03-03T00:00:00Z",
+		},
+		{
+			name:     "strictly after",
+			schedule: CronSchedule{Expression: "0 * * * *"},
+			after:    "2026-03-02T10:00:00Z",
+			want:     "2026-03-02T11:00:00Z",
+		},
+		{
+			name:     "time zone",
+			schedule: CronSchedule{Expression: "0 9 * * *", TimeZone: "America/New_York"},
+			after:    "2026-03-02T10:00:00Z",
+			want:     "2026-03-02T14:00:00Z",
+		},
+		{
+			name:     "time zone after daylight saving starts",
+			schedule: CronSchedule{Expression: "0 9 * * *", TimeZone: "America/New_York"},
+			after:    "2026-03-08T00:00:00Z",
+			want:     "2026-03-08T13:00:00Z",
+		},
+		{
+			name:     "start time is a fire time",
+			schedule: CronSchedule{Frequency: time.Hour, StartTime: at("2026-03-02T12:00:00Z")},
+			after:    "2026-03-02T10:00:00Z",
+			want:     "2026-03-02T12:00:00Z",
+		},
+		{
+			name:     "end time is a fire time",
+			schedule: CronSchedule{Expression: "0 * * * *", EndTime: at("2026-03-02T11:00:00Z")},
+			after:    "2026-03-02T10:00:00Z",
+			want:     "2026-03-02T11:00:00Z",
+		},
+		{
+			name:     "past the end time",
+			schedule: CronSchedule{Expression: "0 * * * *", EndTime: at("2026-03-02T10:30:00Z")},
+			after:    "2026-03-02T10:00:00Z",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, err := tt.schedule.nextFireTime(at(tt.after))
+			require.NoError(t, err)
+			if len(tt.want) == 0 {
+				assert.True(t, next.IsZero(), "unexpected fire time %v", next)
+				return
+			}
+			assert.True(t, at(tt.want).Equal(next), "expected %s, got %v", tt.want, next)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() CronSchedule {
+		return CronSchedule{Frequency: time.Minute, Hostgroups: []string{"hostgroup-1"}}
+	}
+	require.NoError(t, (&CronSchedule{Expression: "0 9 * * 1-5", Hostgroups: []string{"hostgroup-1"}}).Validate())
+
+	tests := []struct {
+		name   string
+		update func(s *CronSchedule)
+	}{
+		{"no hostgroup", func(s *CronSchedule) { s.Hostgroups = nil }},
+		{"no frequency or expression", func(s *CronSchedule) { s.Frequency = 0 }},
+		{"four fields", func(s *CronSchedule) { s.Expression = "0 9 * *" }},
+		{"invalid field", func(s *CronSchedule) { s.Expression = "0 25 * * *" }},
+		{"invalid time zone", func(s *CronSchedule) { s.TimeZone = "Mars/Olympus_Mons" }},
+		{"end before start", func(s *CronSchedule) {
+			s.StartTime = time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+			s.EndTime = s.StartTime.Add(-time.Hour)
+		}},
+		{"invalid overlap", func(s *CronSchedule) { s.Overlap = "queue" }},
+		{"invalid mode", func(s *CronSchedule) { s.Mode = "blue_green" }},
+		{"estimated events budget too large", func(s *CronSchedule) { s.EstimatedEventsPerRun = maxEstimatedEventsPerRun + 1 }},
+		{"invalid blackout", func(s *CronSchedule) {
+			s.Calendar = &Calendar{Blackouts: []BlackoutWindow{{Start: "22:00", End: "24:30"}}}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := valid()
+			tt.update(&schedule)
+			assert.Error(t, schedule.Validate())
+		})
+	}
+}
@@ -0,0 +1,505 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	cadence "go.uber.org/cadence/workflow"
+	"go.uber.org/zap"
+)
+
+// OverlapPolicy is what happens when a fire time is reached while the previous run is still going
+type OverlapPolicy string
+
+const (
+	// OverlapSkip skips the fire time, this is the default
+	OverlapSkip OverlapPolicy = "skip"
+	// OverlapBufferOne runs the fire time once the previous run finished, at most one fire time
+	// is buffered and the others are skipped
+	OverlapBufferOne OverlapPolicy = "buffer_one"
+	// OverlapBufferAll buffers every fire time and runs them one after the other
+	OverlapBufferAll OverlapPolicy = "buffer_all"
+	// OverlapCancelPrevious cancels the previous run, waits for its jobs to stop and then runs the fire time
+	OverlapCancelPrevious OverlapPolicy = "cancel_previous"
+	// OverlapTerminatePrevious abandons the previous run without waiting for its jobs and runs the fire time
+	OverlapTerminatePrevious OverlapPolicy = "terminate_previous"
+)
+
+// QueryState is the query returning the CronState of the workflow
+const QueryState = "state"
+
+const (
+	// maxSkippedTicks is the number of skipped fire times kept in the workflow state
+	maxSkippedTicks = 100
+	// estimatedEventsPerTick, estimatedEventsPerJob and estimatedEventsPerSignal are the history
+	// events counted against the estimated budget of a run of the workflow for a fire time or
+	// blackout timer (timer, jitter marker and decision task), for a job or notification
+	// (activity events, cancellation and decision task) and for a signal. They are guesses,
+	// the budget leaves room for what they miss.
+	estimatedEventsPerTick   = 6
+	estimatedEventsPerJob    = 8
+	estimatedEventsPerSignal = 4
+	// continueAsNewMargin is the time left before the execution timeout at which the
+	// workflow cancels the runs in progress and continues as new, at most half the timeout
+	continueAsNewMargin = 10 * time.Minute
+	// maxBlackoutSkips is the number of blackouts skipped in a row after which the
+	// calendar is deemed to block every fire time
+	maxBlackoutSkips = 1000
+)
+
+type (
+	// CronState is the scheduling state of the Cron workflow, returned by the state query
+	CronState struct {
+		Ticks        int          // fire times reached so far
+		Runs         int          // runs started so far
+		Triggered    int          // runs started by SignalTrigger
+		Running      bool         // whether a run is in progress
+		Paused       bool         // whether the schedule is paused by SignalPause
+		PauseReason  string       // reason given when pausing
+		CanceledRuns int          // runs canceled or terminated by a later fire time
+		SkippedCount int          // fire times skipped because a run was in progress
+		SkippedTicks []time.Time  // most recent skipped fire times
+		Buffered     []time.Time  // fire times waiting for the run in progress
+		NextFireTime time.Time    // next fire time, zero when the schedule has ended or is paused
+		LastRun      *RunResult   // outcome of the last finished run
+		RecentRuns   []*RunResult // outcomes of the most recent runs, oldest first
+		LastFireTime time.Time    // fire time of the last tick, the schedule resumes after it
+		Generation   int          // number of times the workflow continued as new
+		UpdateError  string       // why the last SignalUpdate was rejected, empty when it was applied
+
+		ConsecutiveFailures int // live runs in a row with failed jobs
+		BlackedOut          int // blackouts that skipped or deferred fire times, each counts once
+
+		Backfill        *BackfillState    // backfill in progress
+		LastBackfill    *BackfillState    // last backfill that ended
+		QueuedBackfills []BackfillRequest // backfills buffered behind the one in progress
+		BackfillError   string            // why the last SignalBackfill was rejected
+	}
+
+	// scheduler fires the runs of a cron schedule, runs execute in their own coroutine so that
+	// fire times keep being processed while a run is in progress
+	scheduler struct {
+		ctx         cadence.Context
+		schedule    *CronSchedule
+		state       *CronState
+		runDoneC    cadence.Channel
+		notifyDoneC cadence.Channel
+		cancelRun   cadence.CancelFunc
+		cancelTimer cadence.CancelFunc
+		runID       int
+		outstanding int  // run coroutines that have not reported back
+		notifying   int  // notifications that have not completed
+		ended       bool // no fire time left before the end time
+
+		deadline        time.Time      // the workflow continues as new before reaching its execution timeout
+		deadlineTimer   cadence.Future // fires at the deadline, nil when there is none
+		ticks           int            // fire times reached by this run of the workflow
+		estimatedEvents int            // estimated size of the history of this run of the workflow
+		continuing      bool           // the workflow continues as new once the run in progress finished
+		deadlineHit     bool           // the deadline was reached and the runs in progress canceled
+
+		backfillCtx    cadence.Context
+		backfillCancel cadence.CancelFunc
+		backfillID     int
+		backfillWait   cadence.Future // fires once the blackout holding back the backfill is over
+	}
+
+	// runOutcome is sent by a run coroutine when its run finished
+	runOutcome struct {
+		runID      int
+		backfillID int // set for the runs of a backfill
+		result     *RunResult
+	}
+)
+
+func (s *CronSchedule) validateOverlap() error {
+	switch s.Overlap {
+	case "", OverlapSkip, OverlapBufferOne, OverlapBufferAll, OverlapCancelPrevious, OverlapTerminatePrevious:
+		return nil
+	}
+	return fmt.Errorf("invalid overlap policy %s", s.Overlap)
+}
+
+// runScheduler runs the cron scheduler. Fire times are computed from the previous
+// fire time rather than the current time so that slow jobs do not make the schedule drift.
+// The state carried over from the previous run of the workflow is nil on the first run.
+func runScheduler(ctx cadence.Context, schedule *CronSchedule, carried *CronState) error {
+	s := &scheduler{
+		ctx:         ctx,
+		schedule:    schedule,
+		state:       &CronState{},
+		runDoneC:    cadence.NewChannel(ctx),
+		notifyDoneC: cadence.NewChannel(ctx),
+	}
+	if carried != nil {
+		s.state = carried
+		s.state.Running = false
+	}
+	if timeout := time.Duration(cadence.GetInfo(ctx).ExecutionStartToCloseTimeoutSeconds) * time.Second; timeout > 0 {
+		margin := continueAsNewMargin
+		if margin > timeout/2 {
+			margin = timeout / 2
+		}
+		s.deadline = cadence.Now(ctx).Add(timeout - margin)
+		s.deadlineTimer = cadence.NewTimer(ctx, timeout-margin)
+	}
+	if err := cadence.SetQueryHandler(ctx, QueryState, func() (*CronState, error) {
+		return s.state, nil
+	}); err != nil {
+		return err
+	}
+	if err := cadence.SetQueryHandler(ctx, QueryStatus, func() (*CronStatus, error) {
+		return &CronStatus{Schedule: s.schedule, State: s.state}, nil
+	}); err != nil {
+		return err
+	}
+	return s.run()
+}
+
+func (s *scheduler) run() error {
+	logger := cadence.GetLogger(s.ctx)
+
+	last := cadence.Now(s.ctx)
+	if !s.state.LastFireTime.IsZero() {
+		last = s.state.LastFireTime
+	}
+	var timer cadence.Future
+	var fireTime time.Time
+	var deferredUntil time.Time // end of the blackout the pending fire time is deferred to
+	var timerErr error
+	blackoutSkips := 0
+	// resetTimer drops the pending fire time so that it is computed again
+	resetTimer := func() {
+		if timer != nil {
+			s.cancelTimer()
+			timer = nil
+		}
+		s.state.NextFireTime = time.Time{}
+	}
+
+	pauseC := cadence.GetSignalChannel(s.ctx, SignalPause)
+	resumeC := cadence.GetSignalChannel(s.ctx, SignalResume)
+	triggerC := cadence.GetSignalChannel(s.ctx, SignalTrigger)
+	updateC := cadence.GetSignalChannel(s.ctx, SignalUpdate)
+	backfillC := cadence.GetSignalChannel(s.ctx, SignalBackfill)
+	for {
+		if !s.continuing && s.dueForContinueAsNew() {
+			logger.Info("Cron continuing as new once idle", zap.Int("ticks", s.ticks), zap.Int("estimatedEvents", s.estimatedEvents))
+			s.continuing = true
+		}
+		if s.continuing && s.outstanding == 0 && s.notifying == 0 {
+			return s.continueAsNew()
+		}
+
+		if !s.continuing && !s.state.Paused && !s.state.Running && len(s.state.Buffered) > 0 {
+			next := s.state.Buffered[0]
+			s.state.Buffered = s.state.Buffered[1:]
+			s.startRun(next)
+		}
+		s.runBackfill()
+
+		if timer == nil && !s.state.Paused && s.hasTicksLeft() {
+			next, err := s.schedule.nextFireTime(last)
+			if err != nil {
+				return err
+			}
+			if next.IsZero() {
+				s.ended = true
+				s.state.NextFireTime = time.Time{}
+			} else {
+				runAt, blackedOut, err := s.schedule.blockedUntil(next)
+				if err != nil {
+					return err
+				}
+				if blackedOut {
+					s.state.BlackedOut++
+					if s.schedule.Calendar.Policy != BlackoutDefer {
+						blackoutSkips++
+						if blackoutSkips > maxBlackoutSkips {
+							return errors.New("the calendar blocks every fire time of the schedule")
+						}
+						// skip the fire times up to the end of the blackout
+						logger.Info("Skipping blackout", zap.Time("fireTime", next), zap.Time("until", runAt))
+						last = runAt.Add(-time.Nanosecond)
+						s.state.LastFireTime = last
+						continue
+					}
+					logger.Info("Deferring fire time to the end of the blackout", zap.Time("fireTime", next), zap.Time("runAt", runAt))
+				}
+				blackoutSkips = 0
+
+				fireTime = next
+				deferredUntil = time.Time{}
+				if blackedOut {
+					deferredUntil = runAt
+				}
+				s.state.NextFireTime = runAt
+				if !s.deadline.IsZero() && runAt.After(s.deadline) {
+					// the fire time is handled by the next run of the workflow
+					s.continuing = true
+					continue
+				}
+				delay := runAt.Sub(cadence.Now(s.ctx)) + jitter(s.ctx, s.schedule.Jitter)
+				if delay < 0 {
+					delay = 0
+				}
+				var timerCtx cadence.Context
+				timerCtx, s.cancelTimer = cadence.WithCancel(s.ctx)
+				timer = cadence.NewTimer(timerCtx, delay)
+				s.estimatedEvents += estimatedEventsPerTick
+			}
+		}
+
+		if timer == nil && s.outstanding == 0 && s.notifying == 0 && !s.state.Paused && s.state.Backfill == nil &&
+			len(s.state.QueuedBackfills) == 0 {
+			logger.Info("Cron completed", zap.Int("ticks", s.state.Ticks), zap.Int("runs", s.state.Runs),
+				zap.Int("skipped", s.state.SkippedCount))
+			return nil
+		}
+
+		selector := cadence.NewSelector(s.ctx)
+		if timer != nil {
+			selector.AddFuture(timer, func(f cadence.Future) {
+				timer = nil
+				if timerErr = f.Get(s.ctx, nil); timerErr != nil {
+					return
+				}
+				last = fireTime
+				if !deferredUntil.IsZero() {
+					// the later fire times of the blackout are merged into the deferred run
+					last = deferredUntil.Add(-time.Nanosecond)
+				}
+				s.state.LastFireTime = last
+				s.onTick(fireTime)
+			})
+		}
+		if s.deadlineTimer != nil {
+			selector.AddFuture(s.deadlineTimer, func(f cadence.Future) {
+				s.deadlineTimer = nil
+				s.onDeadline()
+			})
+		}
+		if s.notifying > 0 {
+			selector.AddReceive(s.notifyDoneC, func(c cadence.Channel, more bool) {
+				c.Receive(s.ctx, nil)
+				s.notifying--
+			})
+		}
+		if s.outstanding > 0 {
+			selector.AddReceive(s.runDoneC, func(c cadence.Channel, more bool) {
+				var outcome runOutcome
+				c.Receive(s.ctx, &outcome)
+				s.onRunDone(outcome)
+			})
+		}
+		selector.AddReceive(pauseC, func(c cadence.Channel, more bool) {
+			var reason string
+			c.Receive(s.ctx, &reason)
+			s.estimatedEvents += estimatedEventsPerSignal
+			logger.Info("Cron paused", zap.String("reason", reason))
+			s.state.Paused = true
+			s.state.PauseReason = reason
+			resetTimer()
+		})
+		selector.AddReceive(resumeC, func(c cadence.Channel, more bool) {
+			c.Receive(s.ctx, nil)
+			s.estimatedEvents += estimatedEventsPerSignal
+			if !s.state.Paused {
+				return
+			}
+			logger.Info("Cron resumed")
+			s.state.Paused = false
+			s.state.PauseReason = ""
+			last = cadence.Now(s.ctx)
+		})
+		selector.AddReceive(triggerC, func(c cadence.Channel, more bool) {
+			c.Receive(s.ctx, nil)
+			s.estimatedEvents += estimatedEventsPerSignal
+			now := cadence.Now(s.ctx)
+			logger.Info("Cron triggered", zap.Time("fireTime", now))
+			s.state.Triggered++
+			s.fire(now)
+		})
+		selector.AddReceive(updateC, func(c cadence.Channel, more bool) {
+			var update ScheduleUpdate
+			c.Receive(s.ctx, &update)
+			s.estimatedEvents += estimatedEventsPerSignal
+			updated, err := update.apply(s.schedule)
+			if err != nil {
+				logger.Warn("Rejected schedule update", zap.Error(err))
+				s.state.UpdateError = err.Error()
+				return
+			}
+			logger.Info("Cron schedule updated", zap.String("expression", updated.Expression),
+				zap.Duration("frequency", updated.Frequency), zap.Strings("groups", updated.Hostgroups))
+			*s.schedule = *updated
+			s.state.UpdateError = ""
+			s.ended = false
+			last = cadence.Now(s.ctx)
+			resetTimer()
+		})
+		if s.backfillWait != nil {
+			selector.AddFuture(s.backfillWait, func(f cadence.Future) {
+				s.backfillWait = nil
+			})
+		}
+		selector.AddReceive(backfillC, func(c cadence.Channel, more bool) {
+			var request BackfillRequest
+			c.Receive(s.ctx, &request)
+			s.estimatedEvents += estimatedEventsPerSignal
+			s.onBackfill(request)
+		})
+		selector.Select(s.ctx)
+		if timerErr != nil {
+			return timerErr
+		}
+	}
+}
+
+func (s *scheduler) hasTicksLeft() bool {
+	return !s.continuing && !s.ended && (s.schedule.Count == 0 || s.state.Ticks < s.schedule.Count)
+}
+
+// dueForContinueAsNew returns whether this run of the workflow reached its tick limit or its estimated events budget
+func (s *scheduler) dueForContinueAsNew() bool {
+	return s.ticks >= s.schedule.ticksPerRun() || s.estimatedEvents >= s.schedule.estimatedEventsPerRun()
+}
+
+// continueAsNew starts a new run of the workflow with the same workflow id, carrying
+// over the schedule state so that the history of a single run stays bounded
+func (s *scheduler) continueAsNew() error {
+	s.state.Running = false
+	s.state.Generation++
+	cadence.GetLogger(s.ctx).Info("Cron continuing as new", zap.Int("generation", s.state.Generation),
+		zap.Int("ticks", s.state.Ticks), zap.Int("runs", s.state.Runs), zap.Int("buffered", len(s.state.Buffered)))
+	return cadence.NewContinueAsNewError(s.ctx, Cron, s.schedule, s.state)
+}
+
+// onDeadline continues as new whatever the state of the schedule, even when paused,
+// ended or waiting for a long run. The run and the backfill jobs in progress are
+// canceled so that they report back before the execution timeout. The pending fire
+// times of the backfill are carried over, along with the ones the deadline interrupted.
+func (s *scheduler) onDeadline() {
+	cadence.GetLogger(s.ctx).Info("Cron reached its deadline, continuing as new", zap.Time("deadline", s.deadline),
+		zap.Bool("running", s.state.Running), zap.Bool("backfilling", s.state.Backfill != nil))
+	s.continuing = true
+	s.deadlineHit = true
+	if s.state.Running {
+		s.cancelRun()
+	}
+	if s.state.Backfill != nil && s.backfillCancel != nil {
+		s.backfillCancel()
+		s.backfillCtx = nil
+		s.backfillCancel = nil
+	}
+}
+
+func (s *scheduler) onTick(fireTime time.Time) {
+	s.state.Ticks++
+	s.ticks++
+	s.fire(fireTime)
+}
+
+// fire runs the jobs of the fire time, applying the overlap policy when a run is still in progress
+func (s *scheduler) fire(fireTime time.Time) {
+	if !s.state.Running {
+		if s.continuing {
+			// left to the next run of the workflow
+			s.state.Buffered = append(s.state.Buffered, fireTime)
+		} else {
+			s.startRun(fireTime)
+		}
+		return
+	}
+
+	logger := cadence.GetLogger(s.ctx)
+	switch s.schedule.Overlap {
+	case OverlapBufferOne:
+		if len(s.state.Buffered) == 0 {
+			s.state.Buffered = append(s.state.Buffered, fireTime)
+		} else {
+			s.skip(fireTime)
+		}
+	case OverlapBufferAll:
+		s.state.Buffered = append(s.state.Buffered, fireTime)
+	case OverlapCancelPrevious:
+		// the buffered fire time runs once the canceled run reported back
+		if len(s.state.Buffered) == 0 {
+			logger.Info("Canceling the previous run", zap.Time("fireTime", fireTime))
+			s.cancelRun()
+			s.state.CanceledRuns++
+		} else {
+			s.skip(s.state.Buffered[0])
+		}
+		s.state.Buffered = []time.Time{fireTime}
+	case OverlapTerminatePrevious:
+		logger.Info("Terminating the previous run", zap.Time("fireTime", fireTime))
+		s.cancelRun()
+		s.state.CanceledRuns++
+		s.state.Running = false
+		if s.continuing {
+			s.state.Buffered = []time.Time{fireTime}
+		} else {
+			s.startRun(fireTime)
+		}
+	default:
+		s.skip(fireTime)
+	}
+}
+
+func (s *scheduler) skip(fireTime time.Time) {
+	cadence.GetLogger(s.ctx).Info("Skipping fire time, previous run still in progress", zap.Time("fireTime", fireTime))
+	s.state.SkippedCount++
+	s.state.SkippedTicks = append(s.state.SkippedTicks, fireTime)
+	if len(s.state.SkippedTicks) > maxSkippedTicks {
+		s.state.SkippedTicks = s.state.SkippedTicks[len(s.state.SkippedTicks)-maxSkippedTicks:]
+	}
+}
+
+// startRun runs the jobs of the fire time in a new coroutine
+func (s *scheduler) startRun(fireTime time.Time) {
+	runCtx, cancel := cadence.WithCancel(s.ctx)
+	s.runID++
+	runID := s.runID
+	s.cancelRun = cancel
+	s.outstanding++
+	s.state.Running = true
+	s.state.Runs++
+	s.estimatedEvents += estimatedEventsPerJob * len(s.schedule.Hostgroups)
+
+	cadence.GetLogger(s.ctx).Info("Scheduling jobs", zap.Time("fireTime", fireTime), zap.Int("run", s.state.Runs))
+	// the run keeps the schedule it started with, updates apply to the next runs
+	schedule := *s.schedule
+	cadence.Go(s.ctx, func(ctx cadence.Context) {
+		result := runJobs(runCtx, &schedule, fireTime)
+		s.runDoneC.Send(ctx, runOutcome{runID: runID, result: result})
+	})
+}
+
+// onRunDone records the outcome of a run, runs terminated by a later fire time are ignored
+func (s *scheduler) onRunDone(outcome runOutcome) {
+	s.outstanding--
+	if outcome.backfillID != 0 {
+		s.onBackfillRunDone(outcome)
+		return
+	}
+	if outcome.runID != s.runID {
+		return
+	}
+	s.state.Running = false
+	s.recordRun(outcome.result)
+	s.alert(outcome.result, true)
+	cadence.GetLogger(s.ctx).Info("Cron run finished", zap.Time("fireTime", outcome.result.FireTime),
+		zap.Strings("succeeded", outcome.result.Succeeded), zap.Strings("failed", outcome.result.Failed),
+		zap.Strings("aborted", outcome.result.Aborted))
+}
+
+// recordRun records the outcome of a finished run in the workflow state
+func (s *scheduler) recordRun(result *RunResult) {
+	s.state.LastRun = result
+	s.state.RecentRuns = append(s.state.RecentRuns, result)
+	if len(s.state.RecentRuns) > maxRecentRuns {
+		s.state.RecentRuns = s.state.RecentRuns[len(s.state.RecentRuns)-maxRecentRuns:]
+	}
+}
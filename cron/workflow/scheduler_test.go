@@ -0,0 +1,230 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/cadence/testsuite"
+	cadence "go.uber.org/cadence/workflow"
+)
+
+func TestOverlapPolicies(t *testing.T) {
+	// jobs take 25s while the schedule fires every 10s, so the runs of the
+	// second and third fire times overlap with the first one
+	fireTime := func(n int) time.Time {
+		return testStart.Add(time.Duration(n) * 10 * time.Second)
+	}
+	tests := []struct {
+		overlap      OverlapPolicy
+		runs         int
+		skipped      []time.Time
+		canceledRuns int
+		lastFireTime time.Time
+		recentRuns   int
+	}{
+		{overlap: OverlapSkip, runs: 1, skipped: []time.Time{fireTime(2), fireTime(3)}, lastFireTime: fireTime(1), recentRuns: 1},
+		{overlap: OverlapBufferOne, runs: 2, skipped: []time.Time{fireTime(3)}, lastFireTime: fireTime(2), recentRuns: 2},
+		{overlap: OverlapBufferAll, runs: 3, lastFireTime: fireTime(3), recentRuns: 3},
+		// terminated runs are not recorded
+		{overlap: OverlapTerminatePrevious, runs: 3, canceledRuns: 2, lastFireTime: fireTime(3), recentRuns: 1},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.overlap), func(t *testing.T) {
+			env := newTestEnv()
+			onJobs(env, 25*time.Second)
+			schedule := newTestSchedule("hostgroup-1")
+			schedule.Count = 3
+			schedule.Overlap = tt.overlap
+
+			state := runCron(t, env, schedule, nil).State
+			assert.Equal(t, 3, state.Ticks)
+			assert.Equal(t, tt.runs, state.Runs)
+			assert.Equal(t, len(tt.skipped), state.SkippedCount)
+			require.Len(t, state.SkippedTicks, len(tt.skipped))
+			for i, skipped := range tt.skipped {
+				assert.True(t, skipped.Equal(state.SkippedTicks[i]), "expected %v skipped, got %v", skipped, state.SkippedTicks[i])
+			}
+			assert.Equal(t, tt.canceledRuns, state.CanceledRuns)
+			assert.Empty(t, state.Buffered)
+			assert.False(t, state.Running)
+			require.NotNil(t, state.LastRun)
+			assert.True(t, tt.lastFireTime.Equal(state.LastRun.FireTime))
+			assert.Equal(t, []string{"hostgroup-1"}, state.LastRun.Succeeded)
+			assert.Len(t, state.RecentRuns, tt.recentRuns)
+		})
+	}
+}
+
+func TestOverlapCancelPrevious(t *testing.T) {
+	env := newTestEnv()
+	onJobs(env, 25*time.Second)
+	schedule := newTestSchedule("hostgroup-1")
+	schedule.Count = 3
+	schedule.Overlap = OverlapCancelPrevious
+
+	state := runCron(t, env, schedule, nil).State
+	// how many runs are canceled depends on how fast the canceled jobs stop, the
+	// last fire time always runs to completion
+	assert.True(t, state.CanceledRuns >= 1)
+	assert.Empty(t, state.Buffered)
+	require.NotNil(t, state.LastRun)
+	assert.True(t, testStart.Add(30*time.Second).Equal(state.LastRun.FireTime))
+	assert.Equal(t, []string{"hostgroup-1"}, state.LastRun.Succeeded)
+}
+
+func TestScheduleEndsAtEndTime(t *testing.T) {
+	env := newTestEnv()
+	onJobs(env, 0)
+	schedule := newTestSchedule("hostgroup-1")
+	schedule.Count = 0
+	schedule.EndTime = testStart.Add(35 * time.Second)
+
+	state := runCron(t, env, schedule, nil).State
+	assert.Equal(t, 3, state.Ticks)
+	assert.Equal(t, 3, state.Runs)
+	assert.True(t, state.NextFireTime.IsZero())
+}
+
+func TestContinueAsNewAfterTicksPerRun(t *testing.T) {
+	env := newTestEnv()
+	onJobs(env, 0)
+	schedule := newTestSchedule("hostgroup-1")
+	schedule.Count = 0
+	schedule.TicksPerRun = 3
+
+	env.ExecuteWorkflow(Cron, schedule, nil)
+	requireContinuedAsNew(t, env)
+	state := queryStatus(t, env).State
+	assert.Equal(t, 3, state.Ticks)
+	assert.Equal(t, 3, state.Runs)
+	assert.Equal(t, 1, state.Generation)
+	assert.True(t, testStart.Add(30*time.Second).Equal(state.LastFireTime))
+}
+
+func TestContinueAsNewAtEstimatedEventsBudget(t *testing.T) {
+	env := newTestEnv()
+	onJobs(env, 0)
+	schedule := newTestSchedule("hostgroup-1")
+	schedule.Count = 0
+	// every tick and its single job are estimated at 14 events, the timer of
+	// the third tick goes over the budget
+	schedule.EstimatedEventsPerRun = 30
+
+	env.ExecuteWorkflow(Cron, schedule, nil)
+	requireContinuedAsNew(t, env)
+	state := queryStatus(t, env).State
+	assert.Equal(t, 2, state.Ticks)
+	assert.Equal(t, 2, state.Runs)
+	assert.Equal(t, 1, state.Generation)
+	assert.True(t, testStart.Add(20*time.Second).Equal(state.LastFireTime))
+}
+
+func TestCarriedStateResumesSchedule(t *testing.T) {
+	env := newTestEnv()
+	onJobs(env, 0)
+	schedule := newTestSchedule("hostgroup-1")
+	schedule.Count = 8
+	carried := &CronState{Ticks: 7, Runs: 7, Running: true, LastFireTime: testStart.Add(-5 * time.Second), Generation: 2}
+
+	state := runCron(t, env, schedule, carried).State
+	assert.Equal(t, 8, state.Ticks)
+	assert.Equal(t, 8, state.Runs)
+	assert.Equal(t, 2, state.Generation)
+	require.NotNil(t, state.LastRun)
+	// the fire time is computed from the carried one, not from the start of the workflow
+	assert.True(t, testStart.Add(5*time.Second).Equal(state.LastRun.FireTime))
+}
+
+func TestContinueAsNewAtDeadlineWhilePaused(t *testing.T) {
+	env := newTestEnv()
+	env.SetWorkflowTimeout(time.Hour)
+	onJobs(env, 0)
+	schedule := newTestSchedule("hostgroup-1")
+	schedule.Count = 0
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(SignalPause, "maintenance")
+	}, time.Second)
+
+	env.ExecuteWorkflow(Cron, schedule, nil)
+	requireContinuedAsNew(t, env)
+	state := queryStatus(t, env).State
+	assert.True(t, state.Paused)
+	assert.Equal(t, "maintenance", state.PauseReason)
+	assert.Equal(t, 1, state.Generation)
+	assert.Zero(t, state.Ticks)
+}
+
+// requireContinuedAsNew requires the workflow to have completed by continuing as new
+func requireContinuedAsNew(t *testing.T, env *testsuite.TestWorkflowEnvironment) {
+	require.True(t, env.IsWorkflowCompleted())
+	err := env.GetWorkflowError()
+	require.Error(t, err)
+	_, ok := err.(*cadence.ContinueAsNewError)
+	require.True(t, ok, "expected the workflow to continue as new, got %v", err)
+}
+
+func TestPauseResumeAndTrigger(t *testing.T) {
+	env := newTestEnv()
+	onJobs(env, 0)
+	schedule := newTestSchedule("hostgroup-1")
+	schedule.Count = 2
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(SignalPause, "maintenance")
+	}, 5*time.Second)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(SignalResume, nil)
+	}, time.Minute)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(SignalTrigger, nil)
+	}, 65*time.Second)
+
+	state := runCron(t, env, schedule, nil).State
+	assert.False(t, state.Paused)
+	assert.Empty(t, state.PauseReason)
+	// the schedule resumes from the resume time, a triggered run is not a tick
+	assert.Equal(t, 2, state.Ticks)
+	assert.Equal(t, 1, state.Triggered)
+	assert.Equal(t, 3, state.Runs)
+	require.Len(t, state.RecentRuns, 3)
+	for i, offset := range []time.Duration{65 * time.Second, 70 * time.Second, 80 * time.Second} {
+		assert.True(t, testStart.Add(offset).Equal(state.RecentRuns[i].FireTime), "run %d fired at %v", i, state.RecentRuns[i].FireTime)
+	}
+}
+
+func TestUpdateAppliesToLaterRuns(t *testing.T) {
+	env := newTestEnv()
+	onJobs(env, 25*time.Second)
+	schedule := newTestSchedule("hostgroup-1", "hostgroup-2")
+	schedule.Count = 2
+	schedule.Mode = ModeParallel
+	schedule.Overlap = OverlapBufferOne
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(SignalUpdate, ScheduleUpdate{Hostgroups: []string{"hostgroup-3"}})
+	}, 15*time.Second)
+
+	status := runCron(t, env, schedule, nil)
+	assert.Empty(t, status.State.UpdateError)
+	assert.Equal(t, []string{"hostgroup-3"}, status.Schedule.Hostgroups)
+	require.Len(t, status.State.RecentRuns, 2)
+	// the run in progress keeps the hostgroups it started with
+	assert.ElementsMatch(t, []string{"hostgroup-1", "hostgroup-2"}, status.State.RecentRuns[0].Succeeded)
+	assert.Equal(t, []string{"hostgroup-3"}, status.State.RecentRuns[1].Succeeded)
+}
+
+func TestInvalidUpdateIsRejected(t *testing.T) {
+	env := newTestEnv()
+	onJobs(env, 0)
+	schedule := newTestSchedule("hostgroup-1")
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(SignalUpdate, ScheduleUpdate{Expression: "0 25 * * *"})
+	}, 5*time.Second)
+
+	status := runCron(t, env, schedule, nil)
+	assert.NotEmpty(t, status.State.UpdateError)
+	assert.Equal(t, 10*time.Second, status.Schedule.Frequency)
+	assert.Empty(t, status.Schedule.Expression)
+	require.NotNil(t, status.State.LastRun)
+	assert.True(t, testStart.Add(10*time.Second).Equal(status.State.LastRun.FireTime))
+}
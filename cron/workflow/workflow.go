@@ -1,43 +1,135 @@
 package workflow
 
 import (
-	//"github.com/venkat1109/cadence-codelab/cron/activity"
-	"errors"
-	"go.uber.org/cadence"
-	"go.uber.org/zap"
+	"math/rand"
 	"time"
+
+	"github.com/venkat1109/cadence-codelab/cron/activity"
+	"github.com/venkat1109/cadence-codelab/cron/notify"
+	sdk "go.uber.org/cadence"
+	cadence "go.uber.org/cadence/workflow"
+	"go.uber.org/zap"
 )
 
 type (
 	CronSchedule struct {
-		Count      int           // total number of jobs to schedule
-		Frequency  time.Duration // frequency at which to schedule jobs
+		Count      int           // total number of jobs to schedule, 0 schedules jobs until EndTime
+		Frequency  time.Duration // frequency at which to schedule jobs, used when Expression is empty
+		Expression string        // 5 field cron expression, or 6 fields starting with seconds
+		TimeZone   string        // IANA time zone the expression is evaluated in, UTC by default
+		Jitter     time.Duration // maximum random delay added to each fire time
+		StartTime  time.Time     // no job is scheduled before this time, optional
+		EndTime    time.Time     // no job is scheduled after this time, optional
 		Hostgroups []string      // schedule a job for each one of these hostgroup
+		Job        activity.Job  // job run on every hostgroup, simulated work by default
+
+		JobRetry *sdk.RetryPolicy // retry policy of the jobs, jobMaxAttempts attempts with backoff by default
+
+		Mode          ExecutionMode // how each run is rolled out across the hostgroups, rolling by default
+		MaxInFlight   int           // hostgroups running the job at once, maxJobsPerLoop by default
+		FailureBudget int           // failed hostgroups tolerated per run before the rest are aborted
+
+		Overlap  OverlapPolicy // what happens to a fire time reached while the previous run is going, skip by default
+		Calendar *Calendar     // blackout windows, holidays and business days restricting the runs, optional
+
+		Notifiers          []notify.Config // notified of failed, timed out and recovered jobs
+		AlertAfterFailures int             // failed runs in a row raising an alert, defaultAlertAfterFailures by default
+
+		TicksPerRun int // fire times after which the workflow continues as new, defaultTicksPerRun by default
+		// EstimatedEventsPerRun is the budget of estimated history events after which the
+		// workflow continues as new, defaultEstimatedEventsPerRun by default and at most
+		// maxEstimatedEventsPerRun. The client does not expose the actual history length to
+		// workflow code, so it is estimated from the timers, jobs, notifications and signals
+		// of the run, and the budget stays well below the history size limits of the server.
+		EstimatedEventsPerRun int
 	}
 )
 
+// maxJobsPerLoop is the default number of hostgroups a rolling run has in flight
 const maxJobsPerLoop = 1
 
+// jobMaxAttempts is the number of times a failed or timed out job is attempted
+const jobMaxAttempts = 5
+
+const (
+	defaultTicksPerRun = 500
+	// defaultEstimatedEventsPerRun is a tenth of the 51200 events at which the server
+	// starts warning about the history size, to make up for the estimate
+	defaultEstimatedEventsPerRun = 5000
+	// maxEstimatedEventsPerRun keeps a configured budget below a fifth of that threshold
+	maxEstimatedEventsPerRun = 10000
+)
+
 func init() {
-	cadence.RegisterWorkflow(Cron)
+	cadence.Register(Cron)
 }
 
-// Cron implements the Cron workflow
-func Cron(ctx cadence.Context, schedule *CronSchedule) error {
+// Cron implements the Cron workflow. It runs until the schedule ends, continuing as new
+// every so often with the state of the schedule carried over, nil on the first run.
+func Cron(ctx cadence.Context, schedule *CronSchedule, carried *CronState) error {
 
 	cadence.GetLogger(ctx).Info("Cron started", zap.Int("Count", schedule.Count),
-		zap.Duration("frequency", schedule.Frequency), zap.Strings("groups", schedule.Hostgroups))
+		zap.Duration("frequency", schedule.Frequency), zap.String("expression", schedule.Expression),
+		zap.String("timezone", schedule.TimeZone), zap.Strings("groups", schedule.Hostgroups))
+
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
 
-	activityCtx := cadence.WithActivityOptions(ctx, cadence.ActivityOptions{
+	schedule.Job = schedule.Job.WithDefaults()
+	return runScheduler(ctx, schedule, carried)
+}
+
+// jobOptions returns the activity options of the jobs of the schedule
+func (s *CronSchedule) jobOptions() cadence.ActivityOptions {
+	retryPolicy := s.JobRetry
+	if retryPolicy == nil {
+		// retried attempts resume from the last heartbeat checkpoint
+		retryPolicy = &sdk.RetryPolicy{
+			InitialInterval:    10 * time.Second,
+			BackoffCoefficient: 2,
+			MaximumInterval:    5 * time.Minute,
+			MaximumAttempts:    jobMaxAttempts,
+		}
+	}
+	return cadence.ActivityOptions{
 		ScheduleToStartTimeout: time.Minute,
-		StartToCloseTimeout:    10 * time.Minute,
+		StartToCloseTimeout:    s.Job.Timeout,
 		HeartbeatTimeout:       time.Minute,
-	})
+		// a run canceled by a later fire time waits for its jobs to stop
+		WaitForCancellation: s.Overlap == OverlapCancelPrevious,
+		RetryPolicy:         retryPolicy,
+	}
+}
 
-	return runScheduler(ctx, activityCtx, schedule)
+// ticksPerRun returns the number of fire times after which the workflow continues as new
+func (s *CronSchedule) ticksPerRun() int {
+	if s.TicksPerRun > 0 {
+		return s.TicksPerRun
+	}
+	return defaultTicksPerRun
 }
 
-// runScheduler runs the cron scheduler
-func runScheduler(ctx cadence.Context, activityCtx cadence.Context, schedule *CronSchedule) error {
-	return errors.New("not implemented")
+// estimatedEventsPerRun returns the budget of estimated history events after which the workflow continues as new
+func (s *CronSchedule) estimatedEventsPerRun() int {
+	if s.EstimatedEventsPerRun > 0 {
+		return s.EstimatedEventsPerRun
+	}
+	return defaultEstimatedEventsPerRun
+}
+
+// jitter returns a random delay below max, recorded as a side effect so that
+// replays see the same delay
+func jitter(ctx cadence.Context, max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	var delay time.Duration
+	encoded := cadence.SideEffect(ctx, func(ctx cadence.Context) interface{} {
+		return time.Duration(rand.Int63n(int64(max)))
+	})
+	if err := encoded.Get(&delay); err != nil {
+		return 0
+	}
+	return delay
 }
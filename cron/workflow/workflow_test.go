@@ -0,0 +1,71 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/venkat1109/cadence-codelab/cron/activity"
+	sdk "go.uber.org/cadence"
+	"go.uber.org/cadence/testsuite"
+)
+
+// testStart is the time the test workflows start at, a Monday
+var testStart = time.Date(2026, 3, 2, 10, 30, 0, 0, time.UTC)
+
+// newTestEnv returns a test environment starting the workflow at testStart
+func newTestEnv() *testsuite.TestWorkflowEnvironment {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	env.SetStartTime(testStart)
+	env.SetWorkflowTimeout(24 * time.Hour)
+	return env
+}
+
+// newTestSchedule returns a schedule firing once after ten seconds on the hostgroups,
+// its jobs are not retried
+func newTestSchedule(hostgroups ...string) *CronSchedule {
+	return &CronSchedule{
+		Count:      1,
+		Frequency:  10 * time.Second,
+		Hostgroups: hostgroups,
+		Job:        activity.Job{Name: "test"},
+		JobRetry: &sdk.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2,
+			MaximumAttempts:    1,
+		},
+	}
+}
+
+// onJobs mocks the Cron activity. Jobs take the given workflow time, the first
+// ones fail with the given errors in launch order and the others succeed.
+func onJobs(env *testsuite.TestWorkflowEnvironment, duration time.Duration, errs ...error) {
+	for _, err := range errs {
+		call := env.OnActivity(activity.Cron, mock.Anything, mock.Anything).Return(activity.JobProgress{}, err).Once()
+		if duration > 0 {
+			call.After(duration)
+		}
+	}
+	call := env.OnActivity(activity.Cron, mock.Anything, mock.Anything).Return(activity.JobProgress{}, nil)
+	if duration > 0 {
+		call.After(duration)
+	}
+}
+
+// runCron executes the Cron workflow until it completes and returns its final status
+func runCron(t *testing.T, env *testsuite.TestWorkflowEnvironment, schedule *CronSchedule, carried *CronState) *CronStatus {
+	env.ExecuteWorkflow(Cron, schedule, carried)
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	return queryStatus(t, env)
+}
+
+func queryStatus(t *testing.T, env *testsuite.TestWorkflowEnvironment) *CronStatus {
+	value, err := env.QueryWorkflow(QueryStatus)
+	require.NoError(t, err)
+	var status CronStatus
+	require.NoError(t, value.Get(&status))
+	return &status
+}
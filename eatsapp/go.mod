@@ -4,6 +4,8 @@ go 1.16
 
 replace github.com/apache/thrift => github.com/apache/thrift v0.0.0-20190309152529-a9b748bb0e02
 
+replace github.com/venkat1109/cadence-codelab/common/timeline => ../common/timeline
+
 require (
 	github.com/apache/thrift v0.13.0
 	github.com/cristalhq/jwt/v3 v3.1.0
@@ -20,6 +22,7 @@ require (
 	github.com/uber/cadence v0.22.0
 	github.com/uber/jaeger-client-go v2.23.1+incompatible
 	github.com/uber/tchannel-go v1.16.0
+	github.com/venkat1109/cadence-codelab/common/timeline v0.0.0-00010101000000-000000000000
 	go.temporal.io/sdk v1.9.0
 	go.uber.org/atomic v1.7.0
 	go.uber.org/cadence v0.18.2
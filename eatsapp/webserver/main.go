@@ -14,6 +14,7 @@ import (
 	"trying/helper"
 	"trying/webserver/service"
 	"trying/webserver/service/courier"
+	"trying/webserver/service/cron"
 	"trying/webserver/service/eats"
 	"trying/webserver/service/restaurant"
 
@@ -41,6 +42,7 @@ func main() {
 	http.Handle("/restaurant", restaurant)
 	http.Handle("/courier", courier.NewService(workflowClient))
 	http.Handle("/eats-orders", eats.NewService(workflowClient, restaurant.GetMenu()))
+	http.Handle("/cron", cron.NewService(workflowClient))
 	http.Handle("/", http.FileServer(http.Dir(".")))
 
 	http.HandleFunc("/eats-menu", func(w http.ResponseWriter, r *http.Request) {
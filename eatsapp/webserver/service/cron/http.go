@@ -0,0 +1,142 @@
+package cron
+
+import (
+	"net/http"
+	"time"
+
+	"go.uber.org/cadence/client"
+)
+
+type (
+	// CronService implements the handlers for requests
+	// sent to the cron http service
+	CronService struct {
+		client client.Client
+	}
+
+	// CronListPage models the data displayed in response to GET requests to the cron service.
+	CronListPage struct {
+		Runs  int // runs shown per hostgroup
+		Crons []*CronView
+	}
+
+	// CronView models one Cron workflow on the cron page.
+	CronView struct {
+		WorkflowID string
+		RunID      string
+		Error      string // why the status query failed
+		Status     CronStatus
+		Hostgroups []*HostgroupRuns
+	}
+
+	// HostgroupRuns models the most recent runs of a cron job on one hostgroup, newest first.
+	HostgroupRuns struct {
+		Hostgroup string
+		Runs      []*RunView
+	}
+
+	// RunView models the job of one run on one hostgroup.
+	RunView struct {
+		WorkflowID   string
+		Hostgroup    string
+		FireTime     time.Time
+		RunID        string // workflow run holding the history of the job
+		Backfill     bool
+		Status       string
+		ExitCode     int
+		OutputDigest string
+		Error        string
+		StartTime    time.Time
+		EndTime      time.Time
+		Duration     time.Duration // zero when the run predates job timings
+	}
+
+	// The types below mirror the status query result of the Cron workflow,
+	// which lives outside of this module, keeping the fields shown here.
+
+	// CronStatus is the result of the status query.
+	CronStatus struct {
+		Schedule *CronSchedule
+		State    *CronState
+	}
+
+	// CronSchedule is the schedule of a Cron workflow.
+	CronSchedule struct {
+		Frequency  time.Duration
+		Expression string
+		TimeZone   string
+		Hostgroups []string
+		Job        struct {
+			Name    string
+			Command string
+		}
+	}
+
+	// CronState is the state of a Cron workflow.
+	CronState struct {
+		Runs         int
+		Running      bool
+		Paused       bool
+		PauseReason  string
+		NextFireTime time.Time
+		RecentRuns   []*RunResult
+		UpdateError  string
+
+		ConsecutiveFailures int
+	}
+
+	// RunResult is the outcome of one cron run across the hostgroups.
+	RunResult struct {
+		FireTime time.Time
+		Backfill bool
+		RunID    string
+		Jobs     []JobOutcome
+	}
+
+	// JobOutcome is the outcome of the job of a run on one hostgroup.
+	JobOutcome struct {
+		Hostgroup    string
+		Status       string
+		ExitCode     int
+		OutputDigest string
+		Error        string
+		StartTime    time.Time
+		EndTime      time.Time
+	}
+
+	// jobInput is the input of a job activity, the Job of the Cron workflow.
+	jobInput struct {
+		ScheduledTime time.Time
+	}
+)
+
+const (
+	// cronWorkflowType is the registered name of the Cron workflow
+	cronWorkflowType = "github.com/venkat1109/cadence-codelab/cron/workflow.Cron"
+	// queryStatus is the query returning the CronStatus of a Cron workflow
+	queryStatus = "status"
+
+	// defaultRuns and maxRuns bound the runs shown per hostgroup, the
+	// workflow keeps the outcomes of its 20 most recent runs
+	defaultRuns = 5
+	maxRuns     = 20
+)
+
+// NewService returns a new instance of the CronService object.
+func NewService(c client.Client) *CronService {
+	return &CronService{
+		client: c,
+	}
+}
+
+func (h *CronService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.show(w, r)
+	case "POST":
+		h.update(w, r)
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,220 @@
+package cron
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/common/timeline"
+	s "go.uber.org/cadence/.gen/go/shared"
+	common "trying/webserver/service"
+)
+
+func (h *CronService) show(w http.ResponseWriter, r *http.Request) {
+	workflowID := r.URL.Query().Get("id")
+	runID := r.URL.Query().Get("run_id")
+
+	if len(workflowID) == 0 || len(runID) == 0 {
+		h.listCrons(w, r)
+	} else {
+		h.showRun(w, r, workflowID, runID)
+	}
+}
+
+// showRun renders the tasks and history events of a cron run. When fire_time (unix nanos)
+// and hostgroup are given, only the job of that run on that hostgroup is shown.
+func (h *CronService) showRun(w http.ResponseWriter, r *http.Request, workflowID string, runID string) {
+	tasks, err := timeline.NewTaskGroupExecution(h.client).Transform(workflowID, runID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	fireTime, _ := strconv.ParseInt(r.URL.Query().Get("fire_time"), 10, 64)
+	hostgroup := r.URL.Query().Get("hostgroup")
+	if fireTime > 0 && len(hostgroup) > 0 {
+		filterTaskGroup(tasks, time.Unix(0, fireTime), hostgroup)
+	}
+	common.ViewHandler(w, r, tasks)
+}
+
+// filterTaskGroup keeps the job activity scheduled for the fire time on the hostgroup task
+// list, along with the events of that activity. A job receives the nominal fire time of its
+// run, so the job of a backfill is told apart from a live run active at the same time.
+func filterTaskGroup(tasks *timeline.TaskGroup, fireTime time.Time, hostgroup string) {
+	scheduledIDs := make(map[int64]bool)
+	activityIDs := make(map[string]bool)
+	for _, event := range tasks.History.Events {
+		attributes := event.ActivityTaskScheduledEventAttributes
+		if event.GetEventType() != s.EventTypeActivityTaskScheduled || attributes.TaskList.GetName() != hostgroup {
+			continue
+		}
+		var job jobInput
+		if err := json.NewDecoder(bytes.NewReader(attributes.Input)).Decode(&job); err != nil || !job.ScheduledTime.Equal(fireTime) {
+			continue
+		}
+		scheduledIDs[event.GetEventId()] = true
+		activityIDs[attributes.GetActivityId()] = true
+	}
+
+	var kept []*timeline.Task
+	for _, task := range tasks.Tasks {
+		if scheduledIDs[task.ID] {
+			kept = append(kept, task)
+		}
+	}
+	tasks.Tasks = kept
+
+	var events []*s.HistoryEvent
+	for _, event := range tasks.History.Events {
+		if scheduledIDs[event.GetEventId()] || scheduledIDs[activityScheduledID(event)] ||
+			activityIDs[event.ActivityTaskCancelRequestedEventAttributes.GetActivityId()] {
+			events = append(events, event)
+		}
+	}
+	tasks.History.Events = events
+}
+
+// activityScheduledID returns the id of the scheduling event of the activity an event
+// belongs to, zero for events of other tasks
+func activityScheduledID(event *s.HistoryEvent) int64 {
+	switch event.GetEventType() {
+	case s.EventTypeActivityTaskStarted:
+		return event.ActivityTaskStartedEventAttributes.GetScheduledEventId()
+	case s.EventTypeActivityTaskCompleted:
+		return event.ActivityTaskCompletedEventAttributes.GetScheduledEventId()
+	case s.EventTypeActivityTaskFailed:
+		return event.ActivityTaskFailedEventAttributes.GetScheduledEventId()
+	case s.EventTypeActivityTaskTimedOut:
+		return event.ActivityTaskTimedOutEventAttributes.GetScheduledEventId()
+	case s.EventTypeActivityTaskCanceled:
+		return event.ActivityTaskCanceledEventAttributes.GetScheduledEventId()
+	}
+	return 0
+}
+
+func (h *CronService) listCrons(w http.ResponseWriter, r *http.Request) {
+	page := CronListPage{Runs: defaultRuns}
+	if n, err := strconv.Atoi(r.URL.Query().Get("runs")); err == nil && n > 0 {
+		page.Runs = n
+		if n > maxRuns {
+			page.Runs = maxRuns
+		}
+	}
+
+	executions, err := h.listOpenCrons()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, execution := range executions {
+		cron := &CronView{
+			WorkflowID: execution.Execution.GetWorkflowId(),
+			RunID:      execution.Execution.GetRunId(),
+		}
+		if err := h.queryStatus(cron); err != nil {
+			cron.Error = err.Error()
+		} else {
+			cron.Hostgroups = recentRuns(cron, page.Runs)
+		}
+		page.Crons = append(page.Crons, cron)
+	}
+	sort.Slice(page.Crons, func(i, j int) bool {
+		return page.Crons[i].WorkflowID < page.Crons[j].WorkflowID
+	})
+
+	common.ViewHandler(w, r, page)
+}
+
+// listOpenCrons returns all the open Cron workflows
+func (h *CronService) listOpenCrons() ([]*s.WorkflowExecutionInfo, error) {
+	earliest, latest := int64(0), time.Now().UnixNano()
+	workflowType := cronWorkflowType
+	request := &s.ListOpenWorkflowExecutionsRequest{
+		StartTimeFilter: &s.StartTimeFilter{EarliestTime: &earliest, LatestTime: &latest},
+		TypeFilter:      &s.WorkflowTypeFilter{Name: &workflowType},
+	}
+
+	var executions []*s.WorkflowExecutionInfo
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		resp, err := h.client.ListOpenWorkflow(ctx, request)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		executions = append(executions, resp.Executions...)
+		if len(resp.NextPageToken) == 0 {
+			return executions, nil
+		}
+		request.NextPageToken = resp.NextPageToken
+	}
+}
+
+// queryStatus fills the status of the Cron workflow of the view
+func (h *CronService) queryStatus(cron *CronView) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	value, err := h.client.QueryWorkflow(ctx, cron.WorkflowID, cron.RunID, queryStatus)
+	if err != nil {
+		return err
+	}
+	return value.Get(&cron.Status)
+}
+
+// recentRuns returns the last n runs of each hostgroup, newest first. The hostgroups
+// of the schedule come first, followed by those since removed from it.
+func recentRuns(cron *CronView, n int) []*HostgroupRuns {
+	status := &cron.Status
+	var hostgroups []*HostgroupRuns
+	byName := make(map[string]*HostgroupRuns)
+	add := func(hostgroup string) *HostgroupRuns {
+		hg, ok := byName[hostgroup]
+		if !ok {
+			hg = &HostgroupRuns{Hostgroup: hostgroup}
+			byName[hostgroup] = hg
+			hostgroups = append(hostgroups, hg)
+		}
+		return hg
+	}
+
+	if status.Schedule != nil {
+		for _, hostgroup := range status.Schedule.Hostgroups {
+			add(hostgroup)
+		}
+	}
+	if status.State == nil {
+		return hostgroups
+	}
+	for i := len(status.State.RecentRuns) - 1; i >= 0; i-- {
+		run := status.State.RecentRuns[i]
+		for _, job := range run.Jobs {
+			hg := add(job.Hostgroup)
+			if len(hg.Runs) >= n {
+				continue
+			}
+			view := &RunView{
+				WorkflowID:   cron.WorkflowID,
+				Hostgroup:    job.Hostgroup,
+				FireTime:     run.FireTime,
+				RunID:        run.RunID,
+				Backfill:     run.Backfill,
+				Status:       job.Status,
+				ExitCode:     job.ExitCode,
+				OutputDigest: job.OutputDigest,
+				Error:        job.Error,
+				StartTime:    job.StartTime,
+				EndTime:      job.EndTime,
+			}
+			if !job.StartTime.IsZero() && !job.EndTime.IsZero() {
+				view.Duration = job.EndTime.Sub(job.StartTime).Round(time.Millisecond)
+			}
+			hg.Runs = append(hg.Runs, view)
+		}
+	}
+	return hostgroups
+}
@@ -0,0 +1,55 @@
+package cron
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Signals accepted by the Cron workflow
+const (
+	signalPause   = "pause"
+	signalResume  = "resume"
+	signalTrigger = "trigger"
+)
+
+// update sends the pause, resume or trigger signal of the posted action to a
+// Cron workflow. The signal is applied asynchronously, the cron page shows its
+// effect once the workflow processed it.
+func (h *CronService) update(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	workflowID := r.Form.Get("id")
+	if len(workflowID) == 0 {
+		http.Error(w, "No cron workflow specified!", http.StatusUnprocessableEntity)
+		return
+	}
+
+	var signal string
+	var arg interface{}
+	switch action := r.Form.Get("action"); action {
+	case "pause":
+		signal, arg = signalPause, r.Form.Get("reason")
+	case "resume":
+		signal = signalResume
+	case "trigger":
+		signal = signalTrigger
+	default:
+		http.Error(w, "Invalid update action: "+action, http.StatusUnprocessableEntity)
+		return
+	}
+
+	// signal the current run, the workflow continues as new periodically
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := h.client.SignalWorkflow(ctx, workflowID, "", signal, arg); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/cron", http.StatusFound)
+}
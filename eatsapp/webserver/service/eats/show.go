@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"runtime"
 
+	"github.com/venkat1109/cadence-codelab/common/timeline"
 	s "go.uber.org/cadence/.gen/go/shared"
 
 	//"time"
@@ -41,8 +42,8 @@ func (h *EatsService) showOrder(
 	return service.ViewHandler(w, r, *data)
 }
 
-func (h *EatsService) processExecution(workflowID string, runID string) (*TaskGroup, error) {
-	tf := NewTaskGroupExecution(h.client)
+func (h *EatsService) processExecution(workflowID string, runID string) (*timeline.TaskGroup, error) {
+	tf := timeline.NewTaskGroupExecution(h.client)
 	return tf.Transform(workflowID, runID)
 }
 
@@ -14,8 +14,7 @@ func main() {
 	app.Flags = []cli.Flag{
 		cli.StringFlag{
 			Name:   lib.FlagAddressWithAlias,
-			Value:  "127.0.0.1:7933",
-			Usage:  "host:port for cadence frontend service",
+			Usage:  "host:port for cadence frontend service, default is 127.0.0.1:7933",
 			EnvVar: "CADENCE_CLI_ADDRESS",
 		},
 		cli.StringFlag{
@@ -23,7 +22,28 @@ func main() {
 			Usage:  "cadence workflow domain",
 			EnvVar: "CADENCE_CLI_DOMAIN",
 		},
+		cli.StringFlag{
+			Name:   lib.FlagTransportWithAlias,
+			Usage:  "rpc transport to the cadence frontend, tchannel or grpc",
+			EnvVar: "CADENCE_CLI_TRANSPORT",
+		},
+		cli.StringFlag{
+			Name:   lib.FlagOutputWithAlias,
+			Usage:  "output format, text or json",
+			EnvVar: "CADENCE_CLI_OUTPUT",
+		},
+		cli.StringFlag{
+			Name:   lib.FlagContextWithAlias,
+			Usage:  "named context from ~/.cadence/config.yaml to use instead of the current one",
+			EnvVar: "CADENCE_CLI_CONTEXT",
+		},
+		cli.StringFlag{
+			Name:   lib.FlagAuditLog,
+			Usage:  "json lines file recording every mutating command, default is ~/.cadence/audit.jsonl",
+			EnvVar: "CADENCE_CLI_AUDIT_LOG",
+		},
 	}
+	app.Before = lib.LoadContext
 
 	app.Commands = []cli.Command{
 		{
@@ -47,6 +67,11 @@ func main() {
 					Name:  lib.FlagEmitMetricWithAlias,
 					Usage: "Flag to emit metric",
 				},
+				cli.StringFlag{
+					Name:  lib.FlagDomainDataWithAlias,
+					Usage: "Domain data of key value pairs, in format of 'k1:v1,k2:v2'",
+				},
+				reasonFlag(),
 			},
 			Action: func(c *cli.Context) {
 				lib.RegisterDomain(c)
@@ -73,6 +98,11 @@ func main() {
 					Name:  lib.FlagEmitMetricWithAlias,
 					Usage: "Flag to emit metric",
 				},
+				cli.StringFlag{
+					Name:  lib.FlagDomainDataWithAlias,
+					Usage: "Domain data of key value pairs, in format of 'k1:v1,k2:v2'",
+				},
+				reasonFlag(),
 			},
 			Action: func(c *cli.Context) {
 				lib.UpdateDomain(c)
@@ -86,6 +116,150 @@ func main() {
 				lib.DescribeDomain(c)
 			},
 		},
+		{
+			Name:  "domain",
+			Usage: "Operate on workflow domains",
+			Subcommands: []cli.Command{
+				{
+					Name:    "list",
+					Aliases: []string{"l"},
+					Usage:   "List all workflow domains",
+					Flags: []cli.Flag{
+						cli.IntFlag{
+							Name:  lib.FlagPageSizeWithAlias,
+							Value: 10,
+							Usage: "Result page size",
+						},
+					},
+					Action: func(c *cli.Context) {
+						lib.ListDomains(c)
+					},
+				},
+				{
+					Name:    "deprecate",
+					Aliases: []string{"dep"},
+					Usage:   "Deprecate existing workflow domain",
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  lib.FlagForceWithAlias,
+							Usage: "Skip the confirmation prompt",
+						},
+						reasonFlag(),
+					},
+					Action: func(c *cli.Context) {
+						lib.DeprecateDomain(c)
+					},
+				},
+			},
+		},
+		{
+			Name:    "tasklist",
+			Aliases: []string{"tl"},
+			Usage:   "Inspect task list pollers and backlog",
+			Subcommands: []cli.Command{
+				{
+					Name:    "describe",
+					Aliases: []string{"desc"},
+					Usage:   "Describe pollers and backlog of a task list",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  lib.FlagTaskListWithAlias,
+							Usage: "TaskList",
+						},
+						cli.StringFlag{
+							Name:  lib.FlagTaskListTypeWithAlias,
+							Usage: "TaskList type, decision or activity, default is to describe both",
+						},
+					},
+					Action: func(c *cli.Context) {
+						lib.DescribeTaskList(c)
+					},
+				},
+				{
+					Name:    "list",
+					Aliases: []string{"l", "list-partitions"},
+					Usage:   "Show pollers and backlog of all task lists used by the codelab workers",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  lib.FlagTaskListWithAlias,
+							Usage: "Comma separated task lists to show instead of the codelab ones",
+						},
+						cli.StringFlag{
+							Name:  lib.FlagJobsFile,
+							Value: "cron/jobs.yaml",
+							Usage: "Jobs file of the cron worker, whose hostgroups are shown with the codelab task lists",
+						},
+					},
+					Action: func(c *cli.Context) {
+						lib.ListTaskLists(c)
+					},
+				},
+			},
+		},
+		{
+			Name:  "context",
+			Usage: "Manage named cluster contexts stored in ~/.cadence/config.yaml",
+			Subcommands: []cli.Command{
+				{
+					Name:    "list",
+					Aliases: []string{"l"},
+					Usage:   "List all contexts, the current one is marked with *",
+					Action: func(c *cli.Context) {
+						lib.ListContexts(c)
+					},
+				},
+				{
+					Name:      "use",
+					Usage:     "Switch the current context",
+					ArgsUsage: "<name>",
+					Action: func(c *cli.Context) {
+						lib.UseContext(c)
+					},
+				},
+				{
+					Name:      "add",
+					Usage:     "Add or replace a context",
+					ArgsUsage: "<name>",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  lib.FlagAddressWithAlias,
+							Usage: "host:port for cadence frontend service",
+						},
+						cli.StringFlag{
+							Name:  lib.FlagDomainWithAlias,
+							Usage: "cadence workflow domain",
+						},
+						cli.StringFlag{
+							Name:  lib.FlagTransportWithAlias,
+							Usage: "rpc transport to the cadence frontend, tchannel or grpc",
+						},
+						cli.StringFlag{
+							Name:  lib.FlagOutputWithAlias,
+							Usage: "default output format, text or json",
+						},
+						cli.StringFlag{
+							Name:  lib.FlagTLSCertPath,
+							Usage: "path to the client certificate, grpc transport only",
+						},
+						cli.StringFlag{
+							Name:  lib.FlagTLSKeyPath,
+							Usage: "path to the client private key, grpc transport only",
+						},
+						cli.StringFlag{
+							Name:  lib.FlagTLSCAPath,
+							Usage: "path to the server CA certificate, grpc transport only",
+						},
+						cli.StringFlag{
+							Name:  lib.FlagTLSServerName,
+							Usage: "server name to verify the frontend certificate against, grpc transport only",
+						},
+					},
+					Action: func(c *cli.Context) {
+						lib.AddContext(c)
+					},
+				},
+			},
+		},
 		{
 			Name:  "show",
 			Usage: "show workflow history",
@@ -102,42 +276,119 @@ func main() {
 					Name:  lib.FlagPrintRawTimeWithAlias,
 					Usage: "Print raw time stamp",
 				},
+				cli.StringFlag{
+					Name:  lib.FlagFormatWithAlias,
+					Usage: "Render the history as a timeline diagram instead, mermaid, dot or svg",
+				},
+				cli.StringFlag{
+					Name:  lib.FlagEventTypesWithAlias,
+					Usage: "Comma separated event types to show, e.g. ActivityTaskScheduled,WorkflowExecutionSignaled",
+				},
+				cli.StringFlag{
+					Name:  lib.FlagActivityWithAlias,
+					Usage: "Only show the events of activities with this activity id or activity type",
+				},
 			},
 			Action: func(c *cli.Context) {
 				lib.ShowHistory(c)
 			},
 		},
 		{
-			Name:  "start",
-			Usage: "start a new workflow execution",
+			Name:  "diff",
+			Usage: "align the histories of two workflow executions and show where they diverge",
 			Flags: []cli.Flag{
 				cli.StringFlag{
-					Name:  lib.FlagTaskListWithAlias,
-					Usage: "TaskList",
+					Name:  lib.FlagWorkflowIDWithAlias,
+					Usage: "WorkflowID of the first execution",
 				},
 				cli.StringFlag{
-					Name:  lib.FlagWorkflowIDWithAlias,
-					Usage: "WorkflowID",
+					Name:  lib.FlagRunIDWithAlias,
+					Usage: "RunID of the first execution",
 				},
 				cli.StringFlag{
-					Name:  lib.FlagWorkflowTypeWithAlias,
-					Usage: "WorkflowTypeName",
+					Name:  lib.FlagWorkflowID2WithAlias,
+					Usage: "WorkflowID of the second execution",
+				},
+				cli.StringFlag{
+					Name:  lib.FlagRunID2WithAlias,
+					Usage: "RunID of the second execution",
+				},
+			},
+			Action: func(c *cli.Context) {
+				lib.DiffHistory(c)
+			},
+		},
+		{
+			Name:  "start",
+			Usage: "start a new workflow execution",
+			Flags: append(startWorkflowFlags(),
+				cli.BoolFlag{
+					Name:  lib.FlagWait,
+					Usage: "Block until the workflow execution closes and print its result",
 				},
 				cli.IntFlag{
-					Name:  lib.FlagExecutionTimeoutWithAlias,
-					Usage: "Execution start to close timeout in seconds",
+					Name:  lib.FlagTimeoutWithAlias,
+					Usage: "Maximum seconds to wait for the workflow to close when --wait is set, default is to wait forever",
+				},
+				cli.StringFlag{
+					Name: lib.FlagBatch,
+					Usage: "Start one workflow per line of this json lines file, - reads stdin. Each line may set workflow_type, " +
+						"workflow_id, tasklist, execution_timeout, decision_timeout, cron and args, unset fields default to the flags",
 				},
 				cli.IntFlag{
-					Name:  lib.FlagDecisionTimeoutWithAlias,
-					Usage: "Decision task start to close timeout in seconds",
+					Name:  lib.FlagParallelismWithAlias,
+					Value: 10,
+					Usage: "Number of concurrent starts when --batch is set",
 				},
+				cli.Float64Flag{
+					Name:  lib.FlagRPS,
+					Usage: "Maximum starts per second when --batch is set, default is unlimited",
+				},
+				reasonFlag(),
+			),
+			Action: func(c *cli.Context) {
+				lib.StartWorkflow(c)
+			},
+		},
+		{
+			Name:    "signal-with-start",
+			Aliases: []string{"sws"},
+			Usage:   "signal a workflow execution, starting it first if it is not running",
+			Flags: append(startWorkflowFlags(),
 				cli.StringFlag{
-					Name:  lib.FlagInputWithAlias,
-					Usage: "Input data for the workflow",
+					Name:  lib.FlagNameWithAlias,
+					Usage: "SignalName",
+				},
+				cli.StringFlag{
+					Name:  lib.FlagSignalInputWithAlias,
+					Usage: "Input message assosciated with signal",
+				},
+				reasonFlag(),
+			),
+			Action: func(c *cli.Context) {
+				lib.SignalWithStartWorkflow(c)
+			},
+		},
+		{
+			Name:    "wait",
+			Aliases: []string{"observe"},
+			Usage:   "block until a workflow execution closes and print its result",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  lib.FlagWorkflowIDWithAlias,
+					Usage: "WorkflowID",
+				},
+				cli.StringFlag{
+					Name:  lib.FlagRunIDWithAlias,
+					Usage: "RunID",
+				},
+				cli.IntFlag{
+					Name:  lib.FlagTimeoutWithAlias,
+					Usage: "Maximum seconds to wait for the workflow to close, default is to wait forever",
 				},
 			},
 			Action: func(c *cli.Context) {
-				lib.StartWorkflow(c)
+				lib.WaitWorkflow(c)
 			},
 		},
 		{
@@ -153,6 +404,10 @@ func main() {
 					Name:  lib.FlagRunIDWithAlias,
 					Usage: "RunID",
 				},
+				cli.StringFlag{
+					Name:  lib.FlagReasonWithAlias,
+					Usage: "The reason you want to cancel the workflow, required and recorded in the audit log",
+				},
 			},
 			Action: func(c *cli.Context) {
 				lib.CancelWorkflow(c)
@@ -179,6 +434,7 @@ func main() {
 					Name:  lib.FlagInputWithAlias,
 					Usage: "Input message assosciated with signal",
 				},
+				reasonFlag(),
 			},
 			Action: func(c *cli.Context) {
 				lib.SignalWorkflow(c)
@@ -199,7 +455,7 @@ func main() {
 				},
 				cli.StringFlag{
 					Name:  lib.FlagReasonWithAlias,
-					Usage: "The reason you want to terminate the workflow",
+					Usage: "The reason you want to terminate the workflow, required and recorded in the audit log",
 				},
 			},
 			Action: func(c *cli.Context) {
@@ -244,7 +500,371 @@ func main() {
 				lib.QueryWorkflow(c)
 			},
 		},
+		{
+			Name:    "activity",
+			Aliases: []string{"act"},
+			Usage:   "complete, fail or heartbeat an activity by task token or by workflow and activity id",
+			Subcommands: []cli.Command{
+				{
+					Name:  "complete",
+					Usage: "complete an activity with a json result",
+					Flags: append(activityTargetFlags(),
+						cli.StringFlag{
+							Name:  lib.FlagResultWithAlias,
+							Usage: "Result of the activity in json",
+						},
+						reasonFlag(),
+					),
+					Action: func(c *cli.Context) {
+						lib.CompleteActivity(c)
+					},
+				},
+				{
+					Name:  "fail",
+					Usage: "fail an activity with a reason and json details",
+					Flags: append(activityTargetFlags(),
+						cli.StringFlag{
+							Name:  lib.FlagReasonWithAlias,
+							Usage: "Reason of the failure, required and recorded in the audit log",
+						},
+						cli.StringFlag{
+							Name:  lib.FlagDetailsWithAlias,
+							Usage: "Details of the failure in json",
+						},
+					),
+					Action: func(c *cli.Context) {
+						lib.FailActivity(c)
+					},
+				},
+				{
+					Name:    "heartbeat",
+					Aliases: []string{"hb"},
+					Usage:   "record a heartbeat for an activity with json details",
+					Flags: append(activityTargetFlags(),
+						cli.StringFlag{
+							Name:  lib.FlagDetailsWithAlias,
+							Usage: "Heartbeat details in json",
+						},
+						reasonFlag(),
+					),
+					Action: func(c *cli.Context) {
+						lib.HeartbeatActivity(c)
+					},
+				},
+			},
+		},
+		{
+			Name:  "bench",
+			Usage: "start workflows at a target rate and report throughput and latency percentiles",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  lib.FlagWorkflowTypeWithAlias,
+					Usage: "WorkflowTypeName",
+				},
+				cli.StringFlag{
+					Name:  lib.FlagTaskListWithAlias,
+					Usage: "TaskList",
+				},
+				cli.IntFlag{
+					Name:  lib.FlagExecutionTimeoutWithAlias,
+					Usage: "Execution start to close timeout in seconds",
+				},
+				cli.IntFlag{
+					Name:  lib.FlagDecisionTimeoutWithAlias,
+					Usage: "Decision task start to close timeout in seconds",
+				},
+				cli.StringFlag{
+					Name:  lib.FlagInputWithAlias,
+					Usage: "Input data for every workflow",
+				},
+				cli.IntFlag{
+					Name:  lib.FlagCount,
+					Value: 100,
+					Usage: "Number of workflows to start",
+				},
+				cli.Float64Flag{
+					Name:  lib.FlagRPS,
+					Usage: "Target starts per second, default is as fast as the parallelism allows",
+				},
+				cli.IntFlag{
+					Name:  lib.FlagParallelismWithAlias,
+					Value: 10,
+					Usage: "Number of concurrent starts",
+				},
+				cli.BoolFlag{
+					Name:  lib.FlagWait,
+					Usage: "Wait for the workflows to close and report end to end latency",
+				},
+				cli.IntFlag{
+					Name:  lib.FlagTimeoutWithAlias,
+					Usage: "Maximum seconds to wait for each workflow when --wait is set, default is the execution timeout",
+				},
+				reasonFlag(),
+			},
+			Action: func(c *cli.Context) {
+				lib.Bench(c)
+			},
+		},
+		{
+			Name:  "top",
+			Usage: "live dashboard of open, failed and retrying workflow executions",
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  lib.FlagIntervalWithAlias,
+					Value: 5,
+					Usage: "Refresh interval in seconds",
+				},
+			},
+			Action: func(c *cli.Context) {
+				lib.Top(c)
+			},
+		},
+		{
+			Name:  "stats",
+			Usage: "report close status counts and latencies of closed workflow executions",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  lib.FlagEarliestTimeWithAlias,
+					Usage: "EarliestTime of start time, default is 24 hours ago, supported formats are '2006-01-02T15:04:05Z07:00' and raw UnixNano",
+				},
+				cli.StringFlag{
+					Name:  lib.FlagLatestTimeWithAlias,
+					Usage: "LatestTime of start time, default is now, supported formats are '2006-01-02T15:04:05Z07:00' and raw UnixNano",
+				},
+				cli.StringFlag{
+					Name:  lib.FlagWorkflowTypeWithAlias,
+					Usage: "WorkflowTypeName",
+				},
+				cli.IntFlag{
+					Name:  lib.FlagLimitWithAlias,
+					Value: 500,
+					Usage: "Maximum number of histories to read for activity statistics",
+				},
+				cli.BoolFlag{
+					Name:  lib.FlagSkipHistory,
+					Usage: "Only report close status and duration, without reading histories",
+				},
+			},
+			Action: func(c *cli.Context) {
+				lib.WorkflowStatistics(c)
+			},
+		},
+		{
+			Name:  "cron",
+			Usage: "Inspect and control the schedule of a Cron workflow",
+			Subcommands: []cli.Command{
+				{
+					Name:    "describe",
+					Aliases: []string{"desc", "status"},
+					Usage:   "Show the schedule, next fire time, paused state and recent runs",
+					Flags:   cronTargetFlags(),
+					Action: func(c *cli.Context) {
+						lib.DescribeCron(c)
+					},
+				},
+				{
+					Name:  "pause",
+					Usage: "Stop firing the schedule until it is resumed",
+					Flags: cronControlFlags(),
+					Action: func(c *cli.Context) {
+						lib.PauseCron(c)
+					},
+				},
+				{
+					Name:  "resume",
+					Usage: "Resume firing a paused schedule from now on",
+					Flags: cronControlFlags(),
+					Action: func(c *cli.Context) {
+						lib.ResumeCron(c)
+					},
+				},
+				{
+					Name:  "trigger",
+					Usage: "Run the jobs now, the overlap policy applies when a run is in progress",
+					Flags: cronControlFlags(),
+					Action: func(c *cli.Context) {
+						lib.TriggerCron(c)
+					},
+				},
+				{
+					Name:  "update",
+					Usage: "Update the expression, frequency, time zone or hostgroups of the schedule",
+					Flags: append(cronControlFlags(),
+						cli.StringFlag{
+							Name:  lib.FlagCronSchedule,
+							Usage: "New cron expression, replaces the frequency",
+						},
+						cli.StringFlag{
+							Name:  lib.FlagFrequencyWithAlias,
+							Usage: "New frequency such as 5m, replaces the cron expression",
+						},
+						cli.StringFlag{
+							Name:  lib.FlagTimeZoneWithAlias,
+							Usage: "New IANA time zone the cron expression is evaluated in",
+						},
+						cli.StringFlag{
+							Name:  lib.FlagHostgroupsWithAlias,
+							Usage: "New comma separated hostgroups",
+						},
+					),
+					Action: func(c *cli.Context) {
+						lib.UpdateCron(c)
+					},
+				},
+				{
+					Name:  "backfill",
+					Usage: "Run the fire times of the schedule within a time range, each job receives its nominal fire time",
+					Flags: append(cronControlFlags(),
+						cli.StringFlag{
+							Name:  lib.FlagEarliestTimeWithAlias,
+							Usage: "First fire time of the range, required, supported formats are '2006-01-02T15:04:05Z07:00' and raw UnixNano",
+						},
+						cli.StringFlag{
+							Name:  lib.FlagLatestTimeWithAlias,
+							Usage: "Last fire time of the range, required, supported formats are '2006-01-02T15:04:05Z07:00' and raw UnixNano",
+						},
+						cli.IntFlag{
+							Name:  lib.FlagParallelismWithAlias,
+							Value: 1,
+							Usage: "Number of fire times run at once",
+						},
+						cli.StringFlag{
+							Name:  lib.FlagOverlap,
+							Value: "skip",
+							Usage: "What happens when a backfill is in progress already: skip, buffer_one, buffer_all, cancel_previous or terminate_previous",
+						},
+					),
+					Action: func(c *cli.Context) {
+						lib.BackfillCron(c)
+					},
+				},
+			},
+		},
 	}
 
 	app.Run(os.Args)
 }
+
+// activityTargetFlags returns the flags identifying the activity of an activity subcommand
+func activityTargetFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  lib.FlagTaskTokenWithAlias,
+			Usage: "Base64 encoded task token of the activity",
+		},
+		cli.StringFlag{
+			Name:  lib.FlagWorkflowIDWithAlias,
+			Usage: "WorkflowID, used with activity_id instead of a task token",
+		},
+		cli.StringFlag{
+			Name:  lib.FlagRunIDWithAlias,
+			Usage: "RunID, default is the current run",
+		},
+		cli.StringFlag{
+			Name:  lib.FlagActivityIDWithAlias,
+			Usage: "ActivityID, used with workflow_id instead of a task token",
+		},
+	}
+}
+
+// cronTargetFlags returns the flags identifying the workflow of a cron subcommand
+func cronTargetFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  lib.FlagJobWithAlias,
+			Usage: "Name of the job in the jobs file, its Cron workflow is cron_<name>",
+		},
+		cli.StringFlag{
+			Name:  lib.FlagWorkflowIDWithAlias,
+			Usage: "WorkflowID of the Cron workflow, used instead of --job",
+		},
+		cli.StringFlag{
+			Name:  lib.FlagRunIDWithAlias,
+			Usage: "RunID, default is the current run",
+		},
+	}
+}
+
+// cronControlFlags returns the flags of the cron subcommands that change the schedule
+func cronControlFlags() []cli.Flag {
+	return append(cronTargetFlags(), reasonFlag())
+}
+
+// reasonFlag returns the reason flag every mutating command requires
+func reasonFlag() cli.Flag {
+	return cli.StringFlag{
+		Name:  lib.FlagReasonWithAlias,
+		Usage: "Why the command is run, required and recorded in the audit log",
+	}
+}
+
+// startWorkflowFlags returns the flags shared by start and signal-with-start
+func startWorkflowFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  lib.FlagTaskListWithAlias,
+			Usage: "TaskList",
+		},
+		cli.StringFlag{
+			Name:  lib.FlagWorkflowIDWithAlias,
+			Usage: "WorkflowID",
+		},
+		cli.StringFlag{
+			Name:  lib.FlagWorkflowTypeWithAlias,
+			Usage: "WorkflowTypeName",
+		},
+		cli.IntFlag{
+			Name:  lib.FlagExecutionTimeoutWithAlias,
+			Usage: "Execution start to close timeout in seconds",
+		},
+		cli.IntFlag{
+			Name:  lib.FlagDecisionTimeoutWithAlias,
+			Usage: "Decision task start to close timeout in seconds",
+		},
+		cli.StringFlag{
+			Name:  lib.FlagInputWithAlias,
+			Usage: "Input data for the workflow",
+		},
+		cli.StringFlag{
+			Name:  lib.FlagReusePolicyWithAlias,
+			Usage: "Workflow ID reuse policy, AllowDuplicateFailedOnly, AllowDuplicate, RejectDuplicate or TerminateIfRunning",
+		},
+		cli.StringFlag{
+			Name:  lib.FlagCronSchedule,
+			Usage: "Cron schedule of the workflow, e.g. '*/5 * * * *'",
+		},
+		cli.IntFlag{
+			Name:  lib.FlagRetryInitialInterval,
+			Usage: "Retry policy initial interval in seconds",
+		},
+		cli.Float64Flag{
+			Name:  lib.FlagRetryBackoff,
+			Value: 2.0,
+			Usage: "Retry policy backoff coefficient",
+		},
+		cli.IntFlag{
+			Name:  lib.FlagRetryMaxInterval,
+			Usage: "Retry policy maximum interval in seconds",
+		},
+		cli.IntFlag{
+			Name:  lib.FlagRetryMaxAttempts,
+			Usage: "Retry policy maximum attempts",
+		},
+		cli.IntFlag{
+			Name:  lib.FlagRetryExpiration,
+			Usage: "Retry policy expiration interval in seconds",
+		},
+		cli.StringFlag{
+			Name:  lib.FlagRetryNonRetriableErrors,
+			Usage: "Comma separated error reasons that are not retried",
+		},
+		cli.StringFlag{
+			Name:  lib.FlagMemo,
+			Usage: "Memo of the workflow as a json object",
+		},
+		cli.StringFlag{
+			Name:  lib.FlagSearchAttributes,
+			Usage: "Search attributes of the workflow as a json object",
+		},
+	}
+}
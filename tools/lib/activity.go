@@ -0,0 +1,163 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/urfave/cli"
+	"go.uber.org/cadence"
+)
+
+// activityTarget identifies an activity either by task token or by workflow and activity id
+type activityTarget struct {
+	taskToken  []byte
+	domain     string
+	workflowID string
+	runID      string
+	activityID string
+}
+
+// CompleteActivity completes an activity with the given json result
+func CompleteActivity(c *cli.Context) {
+	wfClient := getWorkflowClient(c)
+	target := getActivityTarget(c)
+	result := parseJSONArg(c, FlagResult)
+	getReason(c)
+
+	ctx, cancel := newContext()
+	defer cancel()
+
+	var err error
+	if len(target.taskToken) > 0 {
+		err = wfClient.CompleteActivity(ctx, target.taskToken, result, nil)
+	} else {
+		err = wfClient.CompleteActivityByID(ctx, target.domain, target.workflowID, target.runID, target.activityID, result, nil)
+	}
+	target.audit(c, err)
+
+	if err != nil {
+		fmt.Printf("Complete activity failed: %v\n", err)
+	} else {
+		fmt.Println("Complete activity succeed.")
+	}
+}
+
+// FailActivity fails an activity with the given reason and json details
+func FailActivity(c *cli.Context) {
+	wfClient := getWorkflowClient(c)
+	target := getActivityTarget(c)
+	reason := getReason(c)
+
+	var failure error
+	if details := parseJSONArg(c, FlagDetails); details != nil {
+		failure = cadence.NewCustomError(reason, details)
+	} else {
+		failure = cadence.NewCustomError(reason)
+	}
+
+	ctx, cancel := newContext()
+	defer cancel()
+
+	var err error
+	if len(target.taskToken) > 0 {
+		err = wfClient.CompleteActivity(ctx, target.taskToken, nil, failure)
+	} else {
+		err = wfClient.CompleteActivityByID(ctx, target.domain, target.workflowID, target.runID, target.activityID, nil, failure)
+	}
+	target.audit(c, err)
+
+	if err != nil {
+		fmt.Printf("Fail activity failed: %v\n", err)
+	} else {
+		fmt.Println("Fail activity succeed.")
+	}
+}
+
+// HeartbeatActivity records a heartbeat with the given json details for an activity
+func HeartbeatActivity(c *cli.Context) {
+	wfClient := getWorkflowClient(c)
+	target := getActivityTarget(c)
+	getReason(c)
+
+	var details []interface{}
+	if d := parseJSONArg(c, FlagDetails); d != nil {
+		details = append(details, d)
+	}
+
+	ctx, cancel := newContext()
+	defer cancel()
+
+	var err error
+	if len(target.taskToken) > 0 {
+		err = wfClient.RecordActivityHeartbeat(ctx, target.taskToken, details...)
+	} else {
+		err = wfClient.RecordActivityHeartbeatByID(ctx, target.domain, target.workflowID, target.runID, target.activityID, details...)
+	}
+	target.audit(c, err)
+
+	if err != nil {
+		fmt.Printf("Heartbeat activity failed: %v\n", err)
+	} else {
+		fmt.Println("Heartbeat activity succeed.")
+	}
+}
+
+// audit writes the audit record of a command on the activity, activities addressed by
+// task token are recorded without their workflow. The token lets anyone holding it complete
+// the activity, so only a short hash of it is written to identify the task.
+func (t *activityTarget) audit(c *cli.Context, err error) {
+	if len(t.taskToken) > 0 {
+		sum := sha256.Sum256(t.taskToken)
+		writeAudit(c, "task_token_sha256:"+hex.EncodeToString(sum[:])[:12], "", err)
+		return
+	}
+	writeAudit(c, t.workflowID+"/"+t.activityID, t.runID, err)
+}
+
+func getActivityTarget(c *cli.Context) *activityTarget {
+	if token := c.String(FlagTaskToken); len(token) > 0 {
+		if c.IsSet(FlagWorkflowID) || c.IsSet(FlagActivityID) {
+			ExitIfError(fmt.Errorf("use either %s or %s and %s, but not both", FlagTaskToken, FlagWorkflowID, FlagActivityID))
+		}
+		return &activityTarget{taskToken: decodeTaskToken(token)}
+	}
+
+	if !c.IsSet(FlagWorkflowID) || !c.IsSet(FlagActivityID) {
+		ExitIfError(fmt.Errorf("either %s or both %s and %s are required", FlagTaskToken, FlagWorkflowID, FlagActivityID))
+	}
+	return &activityTarget{
+		domain:     getRequiredGlobalOption(c, FlagDomain),
+		workflowID: c.String(FlagWorkflowID),
+		runID:      c.String(FlagRunID),
+		activityID: c.String(FlagActivityID),
+	}
+}
+
+// decodeTaskToken decodes a base64 task token, as handed out to the eats web pages
+func decodeTaskToken(token string) []byte {
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if data, err := encoding.DecodeString(token); err == nil {
+			return data
+		}
+	}
+	ExitIfError(errors.New("task token is not valid base64"))
+	return nil
+}
+
+// parseJSONArg decodes the json value of the given flag, returning nil when the flag is unset
+func parseJSONArg(c *cli.Context, flagName string) interface{} {
+	value := c.String(flagName)
+	if len(value) == 0 {
+		return nil
+	}
+
+	var result interface{}
+	if err := json.Unmarshal([]byte(value), &result); err != nil {
+		ExitIfError(fmt.Errorf("%s is not valid json: %v", flagName, err))
+	}
+	return result
+}
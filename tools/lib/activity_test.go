@@ -0,0 +1,27 @@
+package lib
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+func TestDecodeTaskToken(t *testing.T) {
+	token := []byte{0xfb, 0xff, 0xfe, 0x01}
+	for _, encoded := range []string{"+//+AQ==", "-__-AQ==", "+//+AQ", "-__-AQ"} {
+		assert.Equal(t, token, decodeTaskToken(encoded), encoded)
+	}
+}
+
+func TestParseJSONArg(t *testing.T) {
+	set := flag.NewFlagSet("complete", flag.ContinueOnError)
+	set.String(FlagResult, "", "")
+	set.String(FlagDetails, "", "")
+	c := cli.NewContext(nil, set, nil)
+	assert.NoError(t, set.Parse([]string{"--" + FlagResult, `{"courier":"c-1","eta":5}`}))
+
+	assert.Equal(t, map[string]interface{}{"courier": "c-1", "eta": float64(5)}, parseJSONArg(c, FlagResult))
+	assert.Nil(t, parseJSONArg(c, FlagDetails))
+}
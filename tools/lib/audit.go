@@ -0,0 +1,119 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/urfave/cli"
+)
+
+const defaultAuditLogFile = ".cadence/audit.jsonl"
+
+// Audit record results
+const (
+	auditSucceeded = "succeeded"
+	auditFailed    = "failed"
+)
+
+type (
+	// AuditRecord is one line of the audit log, written for every mutating command
+	AuditRecord struct {
+		Time     time.Time `json:"time"`
+		User     string    `json:"user"`
+		Host     string    `json:"host"`
+		Command  string    `json:"command"`
+		Domain   string    `json:"domain,omitempty"`
+		Target   string    `json:"target"`
+		RunID    string    `json:"runId,omitempty"`
+		Reason   string    `json:"reason,omitempty"`
+		Result   string    `json:"result"`
+		Error    string    `json:"error,omitempty"`
+		Identity string    `json:"identity"`
+	}
+)
+
+// getReason returns the reason every mutating command requires, it is recorded in the audit log
+func getReason(c *cli.Context) string {
+	return getRequiredOption(c, FlagReason)
+}
+
+// writeAudit appends the outcome of a mutating command on target to the audit log.
+// The command already ran, so failing to write the record only prints a warning.
+func writeAudit(c *cli.Context, target string, runID string, cmdErr error) {
+	record := &AuditRecord{
+		Time:     time.Now().UTC(),
+		User:     getUserName(),
+		Host:     getHostName(),
+		Command:  c.Command.FullName(),
+		Domain:   c.GlobalString(FlagDomain),
+		Target:   target,
+		RunID:    runID,
+		Reason:   c.String(FlagReason),
+		Result:   auditSucceeded,
+		Identity: getIdentity(),
+	}
+	if cmdErr != nil {
+		record.Result = auditFailed
+		record.Error = cmdErr.Error()
+	}
+
+	if err := appendAuditRecord(auditLogPath(c), record); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write audit record: %v\n", err)
+	}
+}
+
+func appendAuditRecord(path string, record *AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func auditLogPath(c *cli.Context) string {
+	if path := c.GlobalString(FlagAuditLog); len(path) > 0 {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return defaultAuditLogFile
+	}
+	return filepath.Join(home, defaultAuditLogFile)
+}
+
+// getIdentity returns the client identity sent to the server, user@host
+func getIdentity() string {
+	return getUserName() + "@" + getHostName()
+}
+
+func getUserName() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); len(name) > 0 {
+		return name
+	}
+	return "unknown"
+}
+
+func getHostName() string {
+	if host, err := os.Hostname(); err == nil {
+		return host
+	}
+	return "unknown"
+}
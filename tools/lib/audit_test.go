@@ -0,0 +1,88 @@
+package lib
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+// newAuditContext returns the context of the named command writing its audit records to path
+func newAuditContext(t *testing.T, path string, command string, reason string) *cli.Context {
+	global := flag.NewFlagSet("cadence", flag.ContinueOnError)
+	global.String(FlagAuditLog, "", "")
+	global.String(FlagDomain, "", "")
+	require.NoError(t, global.Parse([]string{"--" + FlagAuditLog, path, "--" + FlagDomain, "eats"}))
+
+	set := flag.NewFlagSet(command, flag.ContinueOnError)
+	set.String(FlagReason, "", "")
+	require.NoError(t, set.Parse([]string{"--" + FlagReason, reason}))
+	c := cli.NewContext(nil, set, cli.NewContext(nil, global, nil))
+	c.Command = cli.Command{Name: command}
+	return c
+}
+
+// readAuditLog returns the records of the audit log at path
+func readAuditLog(t *testing.T, path string) []*AuditRecord {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var records []*AuditRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		record := &AuditRecord{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), record))
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+func TestWriteAudit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "logs", "audit.jsonl")
+
+	writeAudit(newAuditContext(t, path, "terminate", "stuck order"), "order-1", "run-1", nil)
+	writeAudit(newAuditContext(t, path, "cancel", "duplicate"), "order-2", "", errors.New("workflow not found"))
+
+	records := readAuditLog(t, path)
+	require.Len(t, records, 2)
+	assert.Equal(t, "terminate", records[0].Command)
+	assert.Equal(t, "eats", records[0].Domain)
+	assert.Equal(t, "order-1", records[0].Target)
+	assert.Equal(t, "run-1", records[0].RunID)
+	assert.Equal(t, "stuck order", records[0].Reason)
+	assert.Equal(t, auditSucceeded, records[0].Result)
+	assert.Equal(t, getIdentity(), records[0].Identity)
+	assert.Equal(t, auditFailed, records[1].Result)
+	assert.Equal(t, "workflow not found", records[1].Error)
+}
+
+func TestActivityAuditHidesTaskToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.jsonl")
+
+	token := []byte("a task token that completes the activity")
+	target := &activityTarget{taskToken: token}
+	target.audit(newAuditContext(t, path, "complete", "courier confirmed by phone"), nil)
+
+	records := readAuditLog(t, path)
+	require.Len(t, records, 1)
+	sum := sha256.Sum256(token)
+	assert.Equal(t, "task_token_sha256:"+hex.EncodeToString(sum[:])[:12], records[0].Target)
+}
@@ -0,0 +1,231 @@
+package lib
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pborman/uuid"
+	"github.com/urfave/cli"
+	"go.uber.org/cadence/client"
+)
+
+// maxBatchLineSize is the longest line accepted in a batch file
+const maxBatchLineSize = 1024 * 1024
+
+type (
+	// BatchStart is one line of a batch file. Unset fields fall back to the
+	// corresponding start flags, a missing workflow id is generated.
+	BatchStart struct {
+		WorkflowType     string            `json:"workflow_type"`
+		WorkflowID       string            `json:"workflow_id"`
+		TaskList         string            `json:"tasklist"`
+		ExecutionTimeout int               `json:"execution_timeout"`
+		DecisionTimeout  int               `json:"decision_timeout"`
+		CronSchedule     string            `json:"cron"`
+		Args             []json.RawMessage `json:"args"`
+	}
+
+	// BatchResult is one line of the result stream, either the started execution or the error
+	BatchResult struct {
+		Line       int    `json:"line"`
+		WorkflowID string `json:"workflowId,omitempty"`
+		RunID      string `json:"runId,omitempty"`
+		Error      string `json:"error,omitempty"`
+	}
+
+	// batchItem is a parsed batch line waiting to be started
+	batchItem struct {
+		line  int
+		start *BatchStart
+		err   error
+	}
+)
+
+// startBatch starts one workflow per line of the batch file with the configured
+// parallelism and rate, and prints the outcome of each line as a json object
+func startBatch(c *cli.Context) {
+	if c.Bool(FlagWait) {
+		ExitIfError(fmt.Errorf("%s is not supported with %s", FlagWait, FlagBatch))
+	}
+	parallelism := c.Int(FlagParallelism)
+	if parallelism <= 0 {
+		ExitIfError(fmt.Errorf("%s must be positive", FlagParallelism))
+	}
+
+	var reader io.Reader = os.Stdin
+	if path := c.String(FlagBatch); path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			ExitIfError(err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	wfClient := getWorkflowClient(c)
+	defaults := &BatchStart{
+		WorkflowType:     c.String(FlagWorkflowType),
+		TaskList:         c.String(FlagTaskList),
+		ExecutionTimeout: c.Int(FlagExecutionTimeout),
+		DecisionTimeout:  c.Int(FlagDecisionTimeout),
+		CronSchedule:     c.String(FlagCronSchedule),
+	}
+	baseOptions := client.StartWorkflowOptions{
+		RetryPolicy:      getRetryPolicy(c),
+		Memo:             parseJSONObject(c, FlagMemo),
+		SearchAttributes: parseJSONObject(c, FlagSearchAttributes),
+	}
+	if c.IsSet(FlagReusePolicy) {
+		baseOptions.WorkflowIDReusePolicy = parseWorkflowIDReusePolicy(c.String(FlagReusePolicy))
+	}
+
+	items := make(chan *batchItem)
+	go readBatch(reader, items)
+
+	var throttle <-chan time.Time
+	if rps := c.Float64(FlagRPS); rps > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / rps))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	var mutex sync.Mutex
+	var total, failed int
+	encoder := json.NewEncoder(os.Stdout)
+	report := func(result *BatchResult) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		total++
+		if len(result.Error) > 0 {
+			failed++
+		}
+		encoder.Encode(result)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range items {
+				if item.err != nil {
+					report(&BatchResult{Line: item.line, Error: item.err.Error()})
+					continue
+				}
+				if throttle != nil {
+					<-throttle
+				}
+				report(startBatchItem(wfClient, item, defaults, baseOptions))
+			}
+		}()
+	}
+	wg.Wait()
+
+	fmt.Fprintf(os.Stderr, "Started %d of %d workflows, %d failed.\n", total-failed, total, failed)
+	var batchErr error
+	if failed > 0 {
+		batchErr = fmt.Errorf("%d of %d starts failed", failed, total)
+	}
+	writeAudit(c, c.String(FlagBatch), "", batchErr)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// readBatch parses the batch lines into items, skipping blank lines, and closes
+// items when the input is exhausted
+func readBatch(reader io.Reader, items chan<- *batchItem) {
+	defer close(items)
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxBatchLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+		item := &batchItem{line: line, start: &BatchStart{}}
+		if err := json.Unmarshal(data, item.start); err != nil {
+			item.err = fmt.Errorf("invalid json: %v", err)
+		}
+		items <- item
+	}
+	if err := scanner.Err(); err != nil {
+		items <- &batchItem{line: line + 1, err: fmt.Errorf("failed to read batch file: %v", err)}
+	}
+}
+
+func startBatchItem(wfClient client.Client, item *batchItem, defaults *BatchStart, baseOptions client.StartWorkflowOptions) *BatchResult {
+	start := item.start
+	if len(start.WorkflowType) == 0 {
+		start.WorkflowType = defaults.WorkflowType
+	}
+	if len(start.WorkflowID) == 0 {
+		start.WorkflowID = uuid.New()
+	}
+	if len(start.TaskList) == 0 {
+		start.TaskList = defaults.TaskList
+	}
+	if start.ExecutionTimeout == 0 {
+		start.ExecutionTimeout = defaults.ExecutionTimeout
+	}
+	if start.DecisionTimeout == 0 {
+		start.DecisionTimeout = defaults.DecisionTimeout
+	}
+	if len(start.CronSchedule) == 0 {
+		start.CronSchedule = defaults.CronSchedule
+	}
+
+	result := &BatchResult{Line: item.line, WorkflowID: start.WorkflowID}
+	if err := validateBatchStart(start); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	args := make([]interface{}, len(start.Args))
+	for i, arg := range start.Args {
+		var value interface{}
+		if err := json.Unmarshal(arg, &value); err != nil {
+			result.Error = fmt.Sprintf("invalid arg %d: %v", i, err)
+			return result
+		}
+		args[i] = value
+	}
+
+	options := baseOptions
+	options.ID = start.WorkflowID
+	options.TaskList = start.TaskList
+	options.ExecutionStartToCloseTimeout = time.Duration(start.ExecutionTimeout) * time.Second
+	options.DecisionTaskStartToCloseTimeout = time.Duration(start.DecisionTimeout) * time.Second
+	options.CronSchedule = start.CronSchedule
+
+	ctx, cancel := newContext()
+	defer cancel()
+	we, err := wfClient.StartWorkflow(ctx, options, start.WorkflowType, args...)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.RunID = we.RunID
+	return result
+}
+
+func validateBatchStart(start *BatchStart) error {
+	switch {
+	case len(start.WorkflowType) == 0:
+		return errors.New("workflow_type is required")
+	case len(start.TaskList) == 0:
+		return errors.New("tasklist is required")
+	case start.ExecutionTimeout <= 0:
+		return errors.New("execution_timeout is required")
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/cadence/client"
+	"go.uber.org/cadence/mocks"
+	"go.uber.org/cadence/workflow"
+)
+
+func TestReadBatch(t *testing.T) {
+	input := `{"workflow_type": "eats", "args": ["o-1", 2]}
+
+not json
+{"workflow_id": "order-3"}
+`
+	items := make(chan *batchItem, 10)
+	readBatch(strings.NewReader(input), items)
+
+	var read []*batchItem
+	for item := range items {
+		read = append(read, item)
+	}
+	require.Len(t, read, 3)
+	// blank lines are skipped but still counted
+	assert.Equal(t, []int{1, 3, 4}, []int{read[0].line, read[1].line, read[2].line})
+	assert.NoError(t, read[0].err)
+	assert.Equal(t, "eats", read[0].start.WorkflowType)
+	assert.Len(t, read[0].start.Args, 2)
+	assert.Error(t, read[1].err)
+	assert.Equal(t, "order-3", read[2].start.WorkflowID)
+}
+
+func TestStartBatchItem(t *testing.T) {
+	defaults := &BatchStart{WorkflowType: "eats", TaskList: "eats-tl", ExecutionTimeout: 60, DecisionTimeout: 10}
+
+	t.Run("defaults", func(t *testing.T) {
+		wfClient := &mocks.Client{}
+		options := mock.MatchedBy(func(options client.StartWorkflowOptions) bool {
+			return options.ID == "order-1" && options.TaskList == "eats-tl" &&
+				options.ExecutionStartToCloseTimeout == time.Minute && options.DecisionTaskStartToCloseTimeout == 10*time.Second
+		})
+		wfClient.On("StartWorkflow", mock.Anything, options, "eats", "o-1", float64(2)).
+			Return(&workflow.Execution{ID: "order-1", RunID: "run-1"}, nil)
+
+		item := &batchItem{line: 1, start: &BatchStart{WorkflowID: "order-1"}}
+		readBatchArgs(t, item.start, `["o-1", 2]`)
+		result := startBatchItem(wfClient, item, defaults, client.StartWorkflowOptions{})
+		assert.Equal(t, &BatchResult{Line: 1, WorkflowID: "order-1", RunID: "run-1"}, result)
+		wfClient.AssertExpectations(t)
+	})
+
+	t.Run("invalid line", func(t *testing.T) {
+		// the mock fails the test when the workflow is started
+		wfClient := &mocks.Client{}
+		item := &batchItem{line: 2, start: &BatchStart{WorkflowID: "order-2"}}
+		result := startBatchItem(wfClient, item, &BatchStart{TaskList: "eats-tl"}, client.StartWorkflowOptions{})
+		assert.Equal(t, &BatchResult{Line: 2, WorkflowID: "order-2", Error: "workflow_type is required"}, result)
+	})
+}
+
+// readBatchArgs sets the args of start as read from a batch line
+func readBatchArgs(t *testing.T, start *BatchStart, args string) {
+	items := make(chan *batchItem, 1)
+	readBatch(strings.NewReader(`{"args": `+args+`}`), items)
+	item := <-items
+	require.NoError(t, item.err)
+	start.Args = item.start.Args
+}
@@ -0,0 +1,231 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pborman/uuid"
+	"github.com/urfave/cli"
+	s "go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/client"
+)
+
+type (
+	// BenchReport summarizes a bench run
+	BenchReport struct {
+		WorkflowType       string         `json:"workflowType"`
+		TaskList           string         `json:"taskList"`
+		Count              int            `json:"count"`
+		Started            int            `json:"started"`
+		StartErrors        int            `json:"startErrors"`
+		Closed             map[string]int `json:"closed,omitempty"`
+		WaitErrors         int            `json:"waitErrors"`
+		Duration           time.Duration  `json:"duration"`
+		StartThroughput    float64        `json:"startThroughput"`
+		CompleteThroughput float64        `json:"completeThroughput,omitempty"`
+		StartLatency       LatencyStats   `json:"startLatency"`
+		EndToEndLatency    LatencyStats   `json:"endToEndLatency"`
+		startLatencies     []time.Duration
+		endToEndLatencies  []time.Duration
+		mutex              sync.Mutex
+	}
+)
+
+// Bench starts count workflows at the target rate, optionally waits for them to
+// close, and reports throughput, latency percentiles and error counts
+func Bench(c *cli.Context) {
+	wfClient := getWorkflowClient(c)
+
+	workflowType := getRequiredOption(c, FlagWorkflowType)
+	taskList := getRequiredOption(c, FlagTaskList)
+	getReason(c)
+	et := c.Int(FlagExecutionTimeout)
+	if et == 0 {
+		ExitIfError(errors.New(FlagExecutionTimeout + " is required"))
+	}
+	count := c.Int(FlagCount)
+	parallelism := c.Int(FlagParallelism)
+	if count <= 0 || parallelism <= 0 {
+		ExitIfError(fmt.Errorf("%s and %s must be positive", FlagCount, FlagParallelism))
+	}
+	wait := c.Bool(FlagWait)
+	waitTimeout := time.Duration(c.Int(FlagTimeout)) * time.Second
+	if waitTimeout == 0 {
+		waitTimeout = time.Duration(et) * time.Second
+	}
+
+	options := client.StartWorkflowOptions{
+		TaskList:                        taskList,
+		ExecutionStartToCloseTimeout:    time.Duration(et) * time.Second,
+		DecisionTaskStartToCloseTimeout: time.Duration(c.Int(FlagDecisionTimeout)) * time.Second,
+	}
+	args := getWorkflowArgs(c)
+	idPrefix := "bench-" + uuid.New()[:8]
+
+	report := &BenchReport{
+		WorkflowType: workflowType,
+		TaskList:     taskList,
+		Count:        count,
+		Closed:       make(map[string]int),
+	}
+
+	var throttle <-chan time.Time
+	if rps := c.Float64(FlagRPS); rps > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / rps))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	indexes := make(chan int)
+	go func() {
+		defer close(indexes)
+		for i := 0; i < count; i++ {
+			if throttle != nil {
+				<-throttle
+			}
+			indexes <- i
+		}
+	}()
+
+	fmt.Fprintf(os.Stderr, "Starting %d %s workflows on %s, ids %s-*\n", count, workflowType, taskList, idPrefix)
+	begin := time.Now()
+	var starters, waiters sync.WaitGroup
+	for i := 0; i < parallelism; i++ {
+		starters.Add(1)
+		go func() {
+			defer starters.Done()
+			for index := range indexes {
+				workflowOptions := options
+				workflowOptions.ID = fmt.Sprintf("%s-%d", idPrefix, index)
+
+				startTime := time.Now()
+				ctx, cancel := newContext()
+				we, err := wfClient.StartWorkflow(ctx, workflowOptions, workflowType, args...)
+				cancel()
+				if err != nil {
+					report.addStart(0, err)
+					continue
+				}
+				report.addStart(time.Since(startTime), nil)
+
+				if wait {
+					waiters.Add(1)
+					go func() {
+						defer waiters.Done()
+						eventType, err := awaitClose(wfClient, we.ID, we.RunID, waitTimeout)
+						report.addClose(time.Since(startTime), eventType, err)
+					}()
+				}
+			}
+		}()
+	}
+	starters.Wait()
+	startDuration := time.Since(begin)
+	waiters.Wait()
+
+	report.Duration = time.Since(begin)
+	var benchErr error
+	if report.StartErrors > 0 {
+		benchErr = fmt.Errorf("%d of %d starts failed", report.StartErrors, count)
+	}
+	writeAudit(c, idPrefix+"-*", "", benchErr)
+	report.StartThroughput = float64(report.Started) / startDuration.Seconds()
+	report.StartLatency = latencyStats(report.startLatencies)
+	if wait {
+		completed := report.Closed[s.EventTypeWorkflowExecutionCompleted.String()]
+		report.CompleteThroughput = float64(completed) / report.Duration.Seconds()
+		report.EndToEndLatency = latencyStats(report.endToEndLatencies)
+	}
+
+	if isJSONOutput(c) {
+		printJSON(report)
+		return
+	}
+	printBenchReport(report, wait)
+}
+
+func (r *BenchReport) addStart(latency time.Duration, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if err != nil {
+		r.StartErrors++
+		return
+	}
+	r.Started++
+	r.startLatencies = append(r.startLatencies, latency)
+}
+
+// addClose records the close event of a workflow, end to end latencies only
+// include the workflows that completed
+func (r *BenchReport) addClose(latency time.Duration, eventType s.EventType, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if err != nil {
+		r.WaitErrors++
+		return
+	}
+	r.Closed[eventType.String()]++
+	if eventType == s.EventTypeWorkflowExecutionCompleted {
+		r.endToEndLatencies = append(r.endToEndLatencies, latency)
+	}
+}
+
+// awaitClose long polls for the close event of an execution, following continue-as-new runs
+func awaitClose(wfClient client.Client, wid string, rid string, timeout time.Duration) (s.EventType, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for {
+		iter := wfClient.GetWorkflowHistory(ctx, wid, rid, true, s.HistoryEventFilterTypeCloseEvent)
+		if !iter.HasNext() {
+			return 0, fmt.Errorf("no close event found for workflow %s", wid)
+		}
+		event, err := iter.Next()
+		if err != nil {
+			return 0, err
+		}
+		if event.GetEventType() != s.EventTypeWorkflowExecutionContinuedAsNew {
+			return event.GetEventType(), nil
+		}
+		rid = event.GetWorkflowExecutionContinuedAsNewEventAttributes().GetNewExecutionRunId()
+	}
+}
+
+func printBenchReport(r *BenchReport, wait bool) {
+	fmt.Printf("Workflow type: %s, task list: %s, duration: %v\n", r.WorkflowType, r.TaskList, r.Duration.Round(time.Millisecond))
+	fmt.Printf("Started: %d of %d, start errors: %d, throughput: %.2f/s\n", r.Started, r.Count, r.StartErrors, r.StartThroughput)
+	if wait {
+		fmt.Printf("Completion throughput: %.2f/s, wait errors: %d\n", r.CompleteThroughput, r.WaitErrors)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nLatency\tP50\tP90\tP99\tMax")
+	fmt.Fprintf(w, "start\t%v\t%v\t%v\t%v\n", r.StartLatency.P50, r.StartLatency.P90, r.StartLatency.P99, r.StartLatency.Max)
+	if wait {
+		l := r.EndToEndLatency
+		fmt.Fprintf(w, "end to end\t%v\t%v\t%v\t%v\n", l.P50, l.P90, l.P99, l.Max)
+	}
+	w.Flush()
+
+	if len(r.Closed) == 0 {
+		return
+	}
+	closeTypes := make([]string, 0, len(r.Closed))
+	for closeType := range r.Closed {
+		closeTypes = append(closeTypes, closeType)
+	}
+	sort.Strings(closeTypes)
+
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nCloseEvent\tCount")
+	for _, closeType := range closeTypes {
+		fmt.Fprintf(w, "%s\t%d\n", closeType, r.Closed[closeType])
+	}
+	w.Flush()
+}
@@ -0,0 +1,40 @@
+package lib
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	s "go.uber.org/cadence/.gen/go/shared"
+)
+
+func TestBenchReport(t *testing.T) {
+	report := &BenchReport{Closed: make(map[string]int)}
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			if i%5 == 0 {
+				err = errors.New("rate limited")
+			}
+			report.addStart(time.Duration(i)*time.Millisecond, err)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 8, report.Started)
+	assert.Equal(t, 2, report.StartErrors)
+	assert.Len(t, report.startLatencies, 8)
+
+	report.addClose(time.Second, s.EventTypeWorkflowExecutionCompleted, nil)
+	report.addClose(2*time.Second, s.EventTypeWorkflowExecutionFailed, nil)
+	report.addClose(0, 0, errors.New("timed out waiting"))
+	assert.Equal(t, map[string]int{"WorkflowExecutionCompleted": 1, "WorkflowExecutionFailed": 1}, report.Closed)
+	assert.Equal(t, 1, report.WaitErrors)
+	// only completed workflows count towards the end to end latency
+	assert.Equal(t, []time.Duration{time.Second}, report.endToEndLatencies)
+}
@@ -2,20 +2,24 @@ package lib
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"runtime/debug"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pborman/uuid"
 	"github.com/urfave/cli"
 	factory "github.com/venkat1109/cadence-codelab/common"
 	"go.uber.org/cadence"
+	"go.uber.org/cadence/.gen/go/cadence/workflowserviceclient"
 	s "go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/client"
 	"go.uber.org/cadence/common"
-	"go.uber.org/cadence/common/util"
 )
 
 /**
@@ -62,10 +66,88 @@ const (
 	FlagEmitMetricWithAlias       = FlagEmitMetric + ", em"
 	FlagName                      = "name"
 	FlagNameWithAlias             = FlagName + ", n"
+	FlagTimeout                   = "timeout"
+	FlagTimeoutWithAlias          = FlagTimeout + ", to"
+	FlagWait                      = "wait"
+	FlagDomainData                = "domain_data"
+	FlagDomainDataWithAlias       = FlagDomainData + ", dmd"
+	FlagForce                     = "force"
+	FlagForceWithAlias            = FlagForce + ", f"
+	FlagTaskListType              = "tasklisttype"
+	FlagTaskListTypeWithAlias     = FlagTaskListType + ", tlt, type"
+	FlagContext                   = "context"
+	FlagContextWithAlias          = FlagContext + ", ctx"
+	FlagTransport                 = "transport"
+	FlagTransportWithAlias        = FlagTransport + ", tr"
+	FlagOutput                    = "output"
+	FlagOutputWithAlias           = FlagOutput + ", o"
+	FlagTLSCertPath               = "tls_cert_path"
+	FlagTLSKeyPath                = "tls_key_path"
+	FlagTLSCAPath                 = "tls_ca_path"
+	FlagTLSServerName             = "tls_server_name"
+	FlagFormat                    = "format"
+	FlagFormatWithAlias           = FlagFormat + ", fmt"
+	FlagLimit                     = "limit"
+	FlagLimitWithAlias            = FlagLimit + ", l"
+	FlagSkipHistory               = "skip_history"
+	FlagTaskToken                 = "task_token"
+	FlagTaskTokenWithAlias        = FlagTaskToken + ", tt"
+	FlagActivityID                = "activity_id"
+	FlagActivityIDWithAlias       = FlagActivityID + ", aid"
+	FlagResult                    = "result"
+	FlagResultWithAlias           = FlagResult + ", res"
+	FlagDetails                   = "details"
+	FlagDetailsWithAlias          = FlagDetails + ", de"
+	FlagReusePolicy               = "workflowidreusepolicy"
+	FlagReusePolicyWithAlias      = FlagReusePolicy + ", wrp"
+	FlagCronSchedule              = "cron"
+	FlagRetryInitialInterval      = "retry_initial_interval"
+	FlagRetryBackoff              = "retry_backoff"
+	FlagRetryMaxInterval          = "retry_max_interval"
+	FlagRetryMaxAttempts          = "retry_max_attempts"
+	FlagRetryExpiration           = "retry_expiration"
+	FlagRetryNonRetriableErrors   = "retry_non_retriable_errors"
+	FlagMemo                      = "memo"
+	FlagSearchAttributes          = "search_attr"
+	FlagSignalInput               = "signal_input"
+	FlagSignalInputWithAlias      = FlagSignalInput + ", si"
+	FlagEventTypes                = "event_types"
+	FlagEventTypesWithAlias       = FlagEventTypes + ", ets"
+	FlagActivity                  = "activity"
+	FlagActivityWithAlias         = FlagActivity + ", act"
+	FlagWorkflowID2               = "workflow_id2"
+	FlagWorkflowID2WithAlias      = FlagWorkflowID2 + ", wid2, w2"
+	FlagRunID2                    = "run_id2"
+	FlagRunID2WithAlias           = FlagRunID2 + ", rid2, r2"
+	FlagBatch                     = "batch"
+	FlagParallelism               = "parallelism"
+	FlagParallelismWithAlias      = FlagParallelism + ", par"
+	FlagRPS                       = "rps"
+	FlagCount                     = "count"
+	FlagInterval                  = "interval"
+	FlagIntervalWithAlias         = FlagInterval + ", in"
+	FlagAuditLog                  = "audit_log"
+	FlagFrequency                 = "frequency"
+	FlagFrequencyWithAlias        = FlagFrequency + ", fq"
+	FlagTimeZone                  = "timezone"
+	FlagTimeZoneWithAlias         = FlagTimeZone + ", tz"
+	FlagHostgroups                = "hostgroups"
+	FlagHostgroupsWithAlias       = FlagHostgroups + ", hg"
+	FlagOverlap                   = "overlap"
+	FlagJobsFile                  = "jobs"
+	FlagJob                       = "job"
+	FlagJobWithAlias              = FlagJob + ", j"
 )
 
 const (
-	localHostPort = "127.0.0.1:7933"
+	localHostPort         = "127.0.0.1:7933"
+	defaultContextTimeout = 30 * time.Second
+)
+
+// Output formats supported by the --output flag
+const (
+	OutputText = "text"
+	OutputJSON = "json"
 )
 
 // ExitIfError exit while err is not nil and print the calling stack also
@@ -86,20 +168,26 @@ func ExitIfError(err error) {
 func RegisterDomain(c *cli.Context) {
 	domainClient := getDomainClient(c)
 	domain := getRequiredGlobalOption(c, FlagDomain)
+	getReason(c)
 
 	description := c.String(FlagDescription)
 	ownerEmail := c.String(FlagOwnerEmail)
 	retentionDays := c.Int(FlagRetentionDays)
 	emitMetric := c.Bool(FlagEmitMetric)
+	data := parseDomainData(c.String(FlagDomainData))
 	request := &s.RegisterDomainRequest{
 		Name:                                   common.StringPtr(domain),
 		Description:                            common.StringPtr(description),
 		OwnerEmail:                             common.StringPtr(ownerEmail),
 		WorkflowExecutionRetentionPeriodInDays: common.Int32Ptr(int32(retentionDays)),
 		EmitMetric:                             common.BoolPtr(emitMetric),
+		Data:                                   data,
 	}
 
-	err := domainClient.Register(request)
+	ctx, cancel := newContext()
+	defer cancel()
+	err := domainClient.Register(ctx, request)
+	writeAudit(c, domain, "", err)
 	if err != nil {
 		if _, ok := err.(*s.DomainAlreadyExistsError); !ok {
 			fmt.Printf("Operation failed: %v.\n", err.Error())
@@ -107,29 +195,52 @@ func RegisterDomain(c *cli.Context) {
 			fmt.Printf("Domain %s already registered.\n", domain)
 		}
 	} else {
-		fmt.Printf("Domain %s succeesfully registered.\n", domain)
+		fmt.Printf("Domain %s successfully registered.\n", domain)
 	}
 }
 
-// UpdateDomain updates a domain
+// UpdateDomain updates a domain. Only the fields whose flags were passed are sent,
+// everything else is left untouched on the server.
 func UpdateDomain(c *cli.Context) {
 	domainClient := getDomainClient(c)
 	domain := getRequiredGlobalOption(c, FlagDomain)
+	getReason(c)
 
-	description := c.String(FlagDescription)
-	ownerEmail := c.String(FlagOwnerEmail)
-	retentionDays := c.Int(FlagRetentionDays)
-	emitMetric := c.Bool(FlagEmitMetric)
-	info := &s.UpdateDomainInfo{
-		Description: common.StringPtr(description),
-		OwnerEmail:  common.StringPtr(ownerEmail),
+	request := &s.UpdateDomainRequest{
+		Name: common.StringPtr(domain),
 	}
-	config := &s.DomainConfiguration{
-		WorkflowExecutionRetentionPeriodInDays: common.Int32Ptr(int32(retentionDays)),
-		EmitMetric:                             common.BoolPtr(emitMetric),
+	if c.IsSet(FlagDescription) || c.IsSet(FlagOwnerEmail) || c.IsSet(FlagDomainData) {
+		info := &s.UpdateDomainInfo{}
+		if c.IsSet(FlagDescription) {
+			info.Description = common.StringPtr(c.String(FlagDescription))
+		}
+		if c.IsSet(FlagOwnerEmail) {
+			info.OwnerEmail = common.StringPtr(c.String(FlagOwnerEmail))
+		}
+		if c.IsSet(FlagDomainData) {
+			info.Data = parseDomainData(c.String(FlagDomainData))
+		}
+		request.UpdatedInfo = info
+	}
+	if c.IsSet(FlagRetentionDays) || c.IsSet(FlagEmitMetric) {
+		config := &s.DomainConfiguration{}
+		if c.IsSet(FlagRetentionDays) {
+			config.WorkflowExecutionRetentionPeriodInDays = common.Int32Ptr(int32(c.Int(FlagRetentionDays)))
+		}
+		if c.IsSet(FlagEmitMetric) {
+			config.EmitMetric = common.BoolPtr(c.Bool(FlagEmitMetric))
+		}
+		request.Configuration = config
+	}
+	if request.UpdatedInfo == nil && request.Configuration == nil {
+		ExitIfError(fmt.Errorf("nothing to update, set at least one of %s, %s, %s, %s or %s",
+			FlagDescription, FlagOwnerEmail, FlagDomainData, FlagRetentionDays, FlagEmitMetric))
 	}
 
-	err := domainClient.Update(domain, info, config)
+	ctx, cancel := newContext()
+	defer cancel()
+	err := domainClient.Update(ctx, request)
+	writeAudit(c, domain, "", err)
 	if err != nil {
 		if _, ok := err.(*s.EntityNotExistsError); !ok {
 			fmt.Printf("Operation failed: %v.\n", err.Error())
@@ -137,7 +248,7 @@ func UpdateDomain(c *cli.Context) {
 			fmt.Printf("Domain %s not exists.\n", domain)
 		}
 	} else {
-		fmt.Printf("Domain %s succeesfully updated.\n", domain)
+		fmt.Printf("Domain %s successfully updated.\n", domain)
 	}
 }
 
@@ -146,7 +257,78 @@ func DescribeDomain(c *cli.Context) {
 	domainClient := getDomainClient(c)
 	domain := getRequiredGlobalOption(c, FlagDomain)
 
-	info, config, err := domainClient.Describe(domain)
+	ctx, cancel := newContext()
+	defer cancel()
+	resp, err := domainClient.Describe(ctx, domain)
+	if err != nil {
+		if _, ok := err.(*s.EntityNotExistsError); !ok {
+			fmt.Printf("Operation failed: %v.\n", err.Error())
+		} else {
+			fmt.Printf("Domain %s not exists.\n", domain)
+		}
+	} else if isJSONOutput(c) {
+		printJSON(resp)
+	} else {
+		printDomain(resp)
+	}
+}
+
+// ListDomains lists all the domains registered with the cadence cluster
+func ListDomains(c *cli.Context) {
+	service := getServiceClient(c)
+	pageSize := c.Int(FlagPageSize)
+
+	var nextPageToken []byte
+	for {
+		request := &s.ListDomainsRequest{
+			PageSize:      common.Int32Ptr(int32(pageSize)),
+			NextPageToken: nextPageToken,
+		}
+
+		ctx, cancel := newContext()
+		resp, err := service.ListDomains(ctx, request)
+		cancel()
+		if err != nil {
+			ExitIfError(err)
+		}
+
+		for _, d := range resp.GetDomains() {
+			if isJSONOutput(c) {
+				printJSON(d)
+			} else {
+				printDomain(d)
+			}
+		}
+
+		nextPageToken = resp.GetNextPageToken()
+		if len(nextPageToken) == 0 {
+			break
+		}
+	}
+}
+
+// DeprecateDomain deprecates a domain after asking the user for confirmation
+func DeprecateDomain(c *cli.Context) {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	getReason(c)
+
+	if !c.Bool(FlagForce) {
+		prompt := fmt.Sprintf("Deprecating domain %s prevents new workflows from being started in it. Continue?", domain)
+		if !confirm(prompt) {
+			fmt.Println("Deprecate domain aborted.")
+			return
+		}
+	}
+
+	service := getServiceClient(c)
+	request := &s.DeprecateDomainRequest{
+		Name: common.StringPtr(domain),
+	}
+
+	ctx, cancel := newContext()
+	defer cancel()
+	err := service.DeprecateDomain(ctx, request)
+	writeAudit(c, domain, "", err)
 	if err != nil {
 		if _, ok := err.(*s.EntityNotExistsError); !ok {
 			fmt.Printf("Operation failed: %v.\n", err.Error())
@@ -154,14 +336,47 @@ func DescribeDomain(c *cli.Context) {
 			fmt.Printf("Domain %s not exists.\n", domain)
 		}
 	} else {
-		fmt.Printf("Name:%v, Description:%v, OwnerEmail:%v, Status:%v, RetentionInDays:%v, EmitMetrics:%v\n",
-			info.GetName(),
-			info.GetDescription(),
-			info.GetOwnerEmail(),
-			info.GetStatus(),
-			config.GetWorkflowExecutionRetentionPeriodInDays(),
-			config.GetEmitMetric())
+		fmt.Printf("Domain %s successfully deprecated.\n", domain)
+	}
+}
+
+func printDomain(resp *s.DescribeDomainResponse) {
+	info := resp.GetDomainInfo()
+	config := resp.GetConfiguration()
+	fmt.Printf("Name:%v, Description:%v, OwnerEmail:%v, Status:%v, RetentionInDays:%v, EmitMetrics:%v, Data:%v\n",
+		info.GetName(),
+		info.GetDescription(),
+		info.GetOwnerEmail(),
+		info.GetStatus(),
+		config.GetWorkflowExecutionRetentionPeriodInDays(),
+		config.GetEmitMetric(),
+		info.GetData())
+}
+
+// parseDomainData parses domain data given as "key1:value1,key2:value2"
+func parseDomainData(str string) map[string]string {
+	result := make(map[string]string)
+	if len(strings.TrimSpace(str)) == 0 {
+		return result
+	}
+
+	for _, pair := range strings.Split(str, ",") {
+		kv := strings.SplitN(pair, ":", 2)
+		if len(kv) != 2 || len(strings.TrimSpace(kv[0])) == 0 {
+			ExitIfError(fmt.Errorf("invalid domain data '%s', use format 'key1:value1,key2:value2'", pair))
+		}
+		result[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
 	}
+	return result
+}
+
+// confirm prompts the user with a yes/no question and returns true on yes
+func confirm(prompt string) bool {
+	fmt.Printf("%s [y/N]: ", prompt)
+	reader := bufio.NewReader(os.Stdin)
+	input, _ := reader.ReadString('\n')
+	input = strings.ToLower(strings.TrimSpace(input))
+	return input == "y" || input == "yes"
 }
 
 // ShowHistory shows the history of given workflow execution based on workflowID and runID.
@@ -172,61 +387,248 @@ func ShowHistory(c *cli.Context) {
 	rid := c.String(FlagRunID)
 	printRawTime := c.Bool(FlagPrintRawTime)
 
-	history, err := wfClient.GetWorkflowHistory(wid, rid)
-	if err != nil {
-		ExitIfError(err)
+	if format := c.String(FlagFormat); len(format) > 0 {
+		renderTimeline(c, wid, rid, format)
+		return
 	}
 
-	for _, e := range history.GetEvents() {
-		if printRawTime {
-			fmt.Printf("%d, %d, %s\n", e.GetEventId(), e.GetTimestamp(), util.HistoryEventToString(e))
-		} else {
-			fmt.Printf("%d, %s, %s\n", e.GetEventId(), convertTime(e.GetTimestamp()), util.HistoryEventToString(e))
+	filter := newHistoryFilter(c.String(FlagEventTypes), c.String(FlagActivity))
+	jsonOutput := isJSONOutput(c)
+
+	ctx, cancel := newContext()
+	defer cancel()
+	iter := wfClient.GetWorkflowHistory(ctx, wid, rid, false, s.HistoryEventFilterTypeAllEvent)
+	for iter.HasNext() {
+		e, err := iter.Next()
+		if err != nil {
+			ExitIfError(err)
 		}
+		if !filter.matches(e) {
+			continue
+		}
+		printHistoryEvent(e, printRawTime, jsonOutput)
 	}
 }
 
-// StartWorkflow starts a new workflow execution
+// StartWorkflow starts a new workflow execution, or one per line of the batch file
 func StartWorkflow(c *cli.Context) {
+	getReason(c)
+	if c.IsSet(FlagBatch) {
+		startBatch(c)
+		return
+	}
 	wfClient := getWorkflowClient(c)
 
-	tasklist := getRequiredOption(c, FlagTaskList)
 	workflowType := getRequiredOption(c, FlagWorkflowType)
+	workflowOptions := getStartWorkflowOptions(c)
+
+	ctx, cancel := newContext()
+	defer cancel()
+
+	we, err := wfClient.StartWorkflow(ctx, workflowOptions, workflowType, getWorkflowArgs(c)...)
+	if err != nil {
+		writeAudit(c, workflowOptions.ID, "", err)
+		fmt.Printf("Failed to create workflow with error: %+v\n", err)
+		return
+	}
+	writeAudit(c, we.ID, we.RunID, nil)
+	fmt.Printf("Started Workflow Id: %s, run Id: %s\n", we.ID, we.RunID)
+
+	if c.Bool(FlagWait) {
+		waitForWorkflow(wfClient, we.ID, we.RunID, c.Int(FlagTimeout))
+	}
+}
+
+// SignalWithStartWorkflow signals a workflow execution, starting it first if it is not running
+func SignalWithStartWorkflow(c *cli.Context) {
+	wfClient := getWorkflowClient(c)
+
+	wid := getRequiredOption(c, FlagWorkflowID)
+	workflowType := getRequiredOption(c, FlagWorkflowType)
+	name := getRequiredOption(c, FlagName)
+	getReason(c)
+	workflowOptions := getStartWorkflowOptions(c)
+
+	var signalArg interface{}
+	if signalInput := c.String(FlagSignalInput); len(signalInput) > 0 {
+		signalArg = signalInput
+	}
+
+	ctx, cancel := newContext()
+	defer cancel()
+
+	we, err := wfClient.SignalWithStartWorkflow(ctx, wid, name, signalArg, workflowOptions, workflowType, getWorkflowArgs(c)...)
+	if err != nil {
+		writeAudit(c, wid, "", err)
+		fmt.Printf("Signal with start workflow failed: %v\n", err)
+		return
+	}
+	writeAudit(c, we.ID, we.RunID, nil)
+	fmt.Printf("Signaled Workflow Id: %s, run Id: %s\n", we.ID, we.RunID)
+}
+
+// getStartWorkflowOptions builds the start options shared by start and signal-with-start
+func getStartWorkflowOptions(c *cli.Context) client.StartWorkflowOptions {
+	tasklist := getRequiredOption(c, FlagTaskList)
 	et := c.Int(FlagExecutionTimeout)
 	if et == 0 {
 		ExitIfError(errors.New(FlagExecutionTimeout + " is required"))
 	}
 	dt := c.Int(FlagDecisionTimeout)
-	if et == 0 {
-		ExitIfError(errors.New(FlagDecisionTimeout + " is required"))
-	}
 	wid := c.String(FlagWorkflowID)
 	if len(wid) == 0 {
 		wid = uuid.New()
 	}
 
-	input := c.String(FlagInput)
-
-	workflowOptions := cadence.StartWorkflowOptions{
+	workflowOptions := client.StartWorkflowOptions{
 		ID:                              wid,
 		TaskList:                        tasklist,
 		ExecutionStartToCloseTimeout:    time.Duration(et) * time.Second,
 		DecisionTaskStartToCloseTimeout: time.Duration(dt) * time.Second,
+		CronSchedule:                    c.String(FlagCronSchedule),
+		RetryPolicy:                     getRetryPolicy(c),
+		Memo:                            parseJSONObject(c, FlagMemo),
+		SearchAttributes:                parseJSONObject(c, FlagSearchAttributes),
+	}
+	if c.IsSet(FlagReusePolicy) {
+		workflowOptions.WorkflowIDReusePolicy = parseWorkflowIDReusePolicy(c.String(FlagReusePolicy))
 	}
+	return workflowOptions
+}
 
-	var we *cadence.WorkflowExecution
-	var err error
+// getWorkflowArgs returns the workflow input, assuming the workflow takes either
+// one input of string type or no input at all
+func getWorkflowArgs(c *cli.Context) []interface{} {
+	input := c.String(FlagInput)
 	if len(input) > 0 {
-		// assume workflow takes one input of string type
-		we, err = wfClient.StartWorkflow(workflowOptions, workflowType, input)
-	} else {
-		// assume workflow takes no input
-		we, err = wfClient.StartWorkflow(workflowOptions, workflowType)
+		return []interface{}{input}
 	}
-	if err != nil {
-		fmt.Printf("Failed to create workflow with error: %+v\n", err)
-	} else {
-		fmt.Printf("Started Workflow Id: %s, run Id: %s\n", we.ID, we.RunID)
+	return nil
+}
+
+// getRetryPolicy returns the workflow retry policy, nil when no retry flag is set
+func getRetryPolicy(c *cli.Context) *cadence.RetryPolicy {
+	if !c.IsSet(FlagRetryInitialInterval) && !c.IsSet(FlagRetryBackoff) && !c.IsSet(FlagRetryMaxInterval) &&
+		!c.IsSet(FlagRetryMaxAttempts) && !c.IsSet(FlagRetryExpiration) && !c.IsSet(FlagRetryNonRetriableErrors) {
+		return nil
+	}
+
+	policy := &cadence.RetryPolicy{
+		InitialInterval:    time.Duration(c.Int(FlagRetryInitialInterval)) * time.Second,
+		BackoffCoefficient: c.Float64(FlagRetryBackoff),
+		MaximumInterval:    time.Duration(c.Int(FlagRetryMaxInterval)) * time.Second,
+		ExpirationInterval: time.Duration(c.Int(FlagRetryExpiration)) * time.Second,
+		MaximumAttempts:    int32(c.Int(FlagRetryMaxAttempts)),
+	}
+	if nonRetriable := c.String(FlagRetryNonRetriableErrors); len(nonRetriable) > 0 {
+		for _, reason := range strings.Split(nonRetriable, ",") {
+			policy.NonRetriableErrorReasons = append(policy.NonRetriableErrorReasons, strings.TrimSpace(reason))
+		}
+	}
+	if policy.InitialInterval <= 0 {
+		ExitIfError(fmt.Errorf("%s must be positive when a retry policy is set", FlagRetryInitialInterval))
+	}
+	if policy.BackoffCoefficient < 1 {
+		ExitIfError(fmt.Errorf("%s must be at least 1", FlagRetryBackoff))
+	}
+	if policy.MaximumAttempts == 0 && policy.ExpirationInterval == 0 {
+		ExitIfError(fmt.Errorf("either %s or %s is required when a retry policy is set", FlagRetryMaxAttempts, FlagRetryExpiration))
+	}
+	return policy
+}
+
+func parseWorkflowIDReusePolicy(value string) client.WorkflowIDReusePolicy {
+	switch strings.ToLower(value) {
+	case "allowduplicatefailedonly":
+		return client.WorkflowIDReusePolicyAllowDuplicateFailedOnly
+	case "allowduplicate":
+		return client.WorkflowIDReusePolicyAllowDuplicate
+	case "rejectduplicate":
+		return client.WorkflowIDReusePolicyRejectDuplicate
+	case "terminateifrunning":
+		return client.WorkflowIDReusePolicyTerminateIfRunning
+	}
+	ExitIfError(fmt.Errorf("invalid %s '%s', must be AllowDuplicateFailedOnly, AllowDuplicate, RejectDuplicate or TerminateIfRunning",
+		FlagReusePolicy, value))
+	return 0
+}
+
+// parseJSONObject decodes the json object value of the given flag, returning nil when the flag is unset
+func parseJSONObject(c *cli.Context, flagName string) map[string]interface{} {
+	value := c.String(flagName)
+	if len(value) == 0 {
+		return nil
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &result); err != nil {
+		ExitIfError(fmt.Errorf("%s must be a json object: %v", flagName, err))
+	}
+	return result
+}
+
+// WaitWorkflow blocks until a workflow execution closes and prints its outcome
+func WaitWorkflow(c *cli.Context) {
+	wfClient := getWorkflowClient(c)
+
+	wid := getRequiredOption(c, FlagWorkflowID)
+	rid := c.String(FlagRunID)
+
+	waitForWorkflow(wfClient, wid, rid, c.Int(FlagTimeout))
+}
+
+// waitForWorkflow long polls for the close event of the given execution, following
+// continue-as-new runs, and exits with a non-zero code unless the execution completed.
+// A timeout of zero waits forever.
+func waitForWorkflow(wfClient client.Client, wid string, rid string, timeoutSecs int) {
+	ctx := context.Background()
+	if timeoutSecs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeoutSecs)*time.Second)
+		defer cancel()
+	}
+
+	for {
+		iter := wfClient.GetWorkflowHistory(ctx, wid, rid, true, s.HistoryEventFilterTypeCloseEvent)
+		if !iter.HasNext() {
+			ExitIfError(fmt.Errorf("no close event found for workflow %s", wid))
+		}
+		event, err := iter.Next()
+		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				err = fmt.Errorf("timed out after %ds waiting for workflow %s", timeoutSecs, wid)
+			}
+			ExitIfError(err)
+		}
+
+		switch event.GetEventType() {
+		case s.EventTypeWorkflowExecutionCompleted:
+			result := event.GetWorkflowExecutionCompletedEventAttributes().GetResult()
+			fmt.Println("Workflow completed.")
+			if len(result) > 0 {
+				fmt.Printf("Result: %s\n", formatPayload(result))
+			}
+			return
+		case s.EventTypeWorkflowExecutionFailed:
+			attr := event.GetWorkflowExecutionFailedEventAttributes()
+			fmt.Printf("Workflow failed, reason: %s, details: %s\n", attr.GetReason(), formatPayload(attr.GetDetails()))
+		case s.EventTypeWorkflowExecutionTimedOut:
+			attr := event.GetWorkflowExecutionTimedOutEventAttributes()
+			fmt.Printf("Workflow timed out, timeout type: %v\n", attr.GetTimeoutType())
+		case s.EventTypeWorkflowExecutionTerminated:
+			attr := event.GetWorkflowExecutionTerminatedEventAttributes()
+			fmt.Printf("Workflow terminated, reason: %s, identity: %s\n", attr.GetReason(), attr.GetIdentity())
+		case s.EventTypeWorkflowExecutionCanceled:
+			attr := event.GetWorkflowExecutionCanceledEventAttributes()
+			fmt.Printf("Workflow canceled, details: %s\n", formatPayload(attr.GetDetails()))
+		case s.EventTypeWorkflowExecutionContinuedAsNew:
+			rid = event.GetWorkflowExecutionContinuedAsNewEventAttributes().GetNewExecutionRunId()
+			fmt.Printf("Workflow continued as new, run Id: %s\n", rid)
+			continue
+		default:
+			fmt.Printf("Workflow closed with unexpected event: %v\n", event.GetEventType())
+		}
+		os.Exit(1)
 	}
 }
 
@@ -236,9 +638,12 @@ func TerminateWorkflow(c *cli.Context) {
 
 	wid := getRequiredOption(c, FlagWorkflowID)
 	rid := c.String(FlagRunID)
-	reason := c.String(FlagReason)
+	reason := getReason(c)
 
-	err := wfClient.TerminateWorkflow(wid, rid, reason, nil)
+	ctx, cancel := newContext()
+	defer cancel()
+	err := wfClient.TerminateWorkflow(ctx, wid, rid, reason, nil)
+	writeAudit(c, wid, rid, err)
 
 	if err != nil {
 		fmt.Printf("Terminate workflow failed: %v\n", err)
@@ -253,8 +658,12 @@ func CancelWorkflow(c *cli.Context) {
 
 	wid := getRequiredOption(c, FlagWorkflowID)
 	rid := c.String(FlagRunID)
+	reason := getReason(c)
 
-	err := wfClient.CancelWorkflow(wid, rid)
+	ctx, cancel := newContext()
+	defer cancel()
+	err := wfClient.CancelWorkflow(ctx, wid, rid, client.WithCancelReason(reason))
+	writeAudit(c, wid, rid, err)
 
 	if err != nil {
 		fmt.Printf("Cancel workflow failed: %v\n", err)
@@ -271,13 +680,18 @@ func SignalWorkflow(c *cli.Context) {
 	rid := c.String(FlagRunID)
 	name := getRequiredOption(c, FlagName)
 	input := c.String(FlagInput)
+	getReason(c)
+
+	ctx, cancel := newContext()
+	defer cancel()
 
 	var err error
 	if len(input) > 0 {
-		err = wfClient.SignalWorkflow(wid, rid, name, input)
+		err = wfClient.SignalWorkflow(ctx, wid, rid, name, input)
 	} else {
-		err = wfClient.SignalWorkflow(wid, rid, name, nil)
+		err = wfClient.SignalWorkflow(ctx, wid, rid, name, nil)
 	}
+	writeAudit(c, wid, rid, err)
 
 	if err != nil {
 		fmt.Printf("Signal workflow failed: %v\n", err)
@@ -313,6 +727,10 @@ func QueryWorkflow(c *cli.Context) {
 		}
 
 		for _, e := range result {
+			if isJSONOutput(c) {
+				printJSON(e)
+				continue
+			}
 			fmt.Printf("%s, -w %s -r %s", e.GetType().GetName(), e.GetExecution().GetWorkflowId(), e.GetExecution().GetRunId())
 			if printRawTime {
 				fmt.Printf(" [%d, %d]\n", e.GetStartTime(), e.GetCloseTime())
@@ -336,7 +754,7 @@ func QueryWorkflow(c *cli.Context) {
 	}
 }
 
-func queryOpenWorkflow(client cadence.Client, pageSize int, earliestTime, latestTime int64, workflowID, workflowType string, nextPageToken []byte) ([]*s.WorkflowExecutionInfo, []byte) {
+func queryOpenWorkflow(wfClient client.Client, pageSize int, earliestTime, latestTime int64, workflowID, workflowType string, nextPageToken []byte) ([]*s.WorkflowExecutionInfo, []byte) {
 	request := &s.ListOpenWorkflowExecutionsRequest{
 		MaximumPageSize: common.Int32Ptr(int32(pageSize)),
 		NextPageToken:   nextPageToken,
@@ -352,14 +770,16 @@ func queryOpenWorkflow(client cadence.Client, pageSize int, earliestTime, latest
 		request.TypeFilter = &s.WorkflowTypeFilter{Name: common.StringPtr(workflowType)}
 	}
 
-	response, err := client.ListOpenWorkflow(request)
+	ctx, cancel := newContext()
+	defer cancel()
+	response, err := wfClient.ListOpenWorkflow(ctx, request)
 	if err != nil {
 		ExitIfError(err)
 	}
 	return response.GetExecutions(), response.GetNextPageToken()
 }
 
-func queryClosedWorkflow(client cadence.Client, pageSize int, earliestTime, latestTime int64, workflowID, workflowType string, nextPageToken []byte) ([]*s.WorkflowExecutionInfo, []byte) {
+func queryClosedWorkflow(wfClient client.Client, pageSize int, earliestTime, latestTime int64, workflowID, workflowType string, nextPageToken []byte) ([]*s.WorkflowExecutionInfo, []byte) {
 	request := &s.ListClosedWorkflowExecutionsRequest{
 		MaximumPageSize: common.Int32Ptr(int32(pageSize)),
 		NextPageToken:   nextPageToken,
@@ -375,17 +795,17 @@ func queryClosedWorkflow(client cadence.Client, pageSize int, earliestTime, late
 		request.TypeFilter = &s.WorkflowTypeFilter{Name: common.StringPtr(workflowType)}
 	}
 
-	response, err := client.ListClosedWorkflow(request)
+	ctx, cancel := newContext()
+	defer cancel()
+	response, err := wfClient.ListClosedWorkflow(ctx, request)
 	if err != nil {
 		ExitIfError(err)
 	}
 	return response.GetExecutions(), response.GetNextPageToken()
 }
 
-func getDomainClient(c *cli.Context) cadence.DomainClient {
-	address := c.GlobalString(FlagAddress)
-
-	builder := getBuilder(address)
+func getDomainClient(c *cli.Context) client.DomainClient {
+	builder := getBuilder(c)
 	domainClient, err := builder.BuildCadenceDomainClient()
 	if err != nil {
 		ExitIfError(err)
@@ -393,11 +813,18 @@ func getDomainClient(c *cli.Context) cadence.DomainClient {
 	return domainClient
 }
 
-func getWorkflowClient(c *cli.Context) cadence.Client {
-	address := c.GlobalString(FlagAddress)
+func getServiceClient(c *cli.Context) workflowserviceclient.Interface {
+	service, err := getBuilder(c).BuildServiceClient()
+	if err != nil {
+		ExitIfError(err)
+	}
+	return service
+}
+
+func getWorkflowClient(c *cli.Context) client.Client {
 	domain := getRequiredGlobalOption(c, FlagDomain)
 
-	builder := getBuilder(address).SetDomain(domain)
+	builder := getBuilder(c).SetDomain(domain)
 	wfClient, err := builder.BuildCadenceClient()
 	if err != nil {
 		ExitIfError(err)
@@ -422,15 +849,60 @@ func getRequiredGlobalOption(c *cli.Context, optionName string) string {
 	return value
 }
 
-func getBuilder(address string) *factory.WorkflowClientBuilder {
-	builder := factory.NewBuilder()
+func getRequiredArg(c *cli.Context, argName string) string {
+	value := c.Args().First()
+	if len(value) == 0 {
+		ExitIfError(fmt.Errorf("%s argument is required", argName))
+	}
+	return value
+}
+
+func getBuilder(c *cli.Context) *factory.WorkflowClientBuilder {
+	address := c.GlobalString(FlagAddress)
+	transport := c.GlobalString(FlagTransport)
+	cfg, err := selectedContext(c)
+	ExitIfError(err)
+	tlsConfig, err := buildTLSConfig(contextTLS(cfg, address, transport))
+	ExitIfError(err)
+
 	if len(address) == 0 {
 		address = localHostPort
 	}
-	builder = builder.SetHostPort(address)
+	builder := factory.NewBuilder().
+		SetHostPort(address).
+		SetTransport(transport).
+		SetTLSConfig(tlsConfig).
+		SetDataConverter(dataConverter).
+		SetClientIdentity(getIdentity())
 	return builder
 }
 
+func isJSONOutput(c *cli.Context) bool {
+	output := c.GlobalString(FlagOutput)
+	ExitIfError(validateOutput(output))
+	return output == OutputJSON
+}
+
+func validateOutput(output string) error {
+	switch output {
+	case "", OutputText, OutputJSON:
+		return nil
+	}
+	return fmt.Errorf("invalid %s '%s', must be %s or %s", FlagOutput, output, OutputText, OutputJSON)
+}
+
+func printJSON(v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		ExitIfError(err)
+	}
+	fmt.Println(string(data))
+}
+
+func newContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), defaultContextTimeout)
+}
+
 func convertTime(unixNano int64) string {
 	t2 := time.Unix(0, unixNano)
 	return t2.Format(time.RFC3339)
@@ -0,0 +1,74 @@
+package lib
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/client"
+)
+
+func TestParseDomainData(t *testing.T) {
+	tests := []struct {
+		name string
+		str  string
+		want map[string]string
+	}{
+		{name: "empty", str: " ", want: map[string]string{}},
+		{name: "pairs", str: "team:eats, tier : 1", want: map[string]string{"team": "eats", "tier": "1"}},
+		{name: "value with colon", str: "url:http://eats", want: map[string]string{"url": "http://eats"}},
+		{name: "empty value", str: "owner:", want: map[string]string{"owner": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseDomainData(tt.str))
+		})
+	}
+}
+
+func TestParseWorkflowIDReusePolicy(t *testing.T) {
+	tests := map[string]client.WorkflowIDReusePolicy{
+		"AllowDuplicateFailedOnly": client.WorkflowIDReusePolicyAllowDuplicateFailedOnly,
+		"allowduplicate":           client.WorkflowIDReusePolicyAllowDuplicate,
+		"RejectDuplicate":          client.WorkflowIDReusePolicyRejectDuplicate,
+		"TERMINATEIFRUNNING":       client.WorkflowIDReusePolicyTerminateIfRunning,
+	}
+	for value, want := range tests {
+		assert.Equal(t, want, parseWorkflowIDReusePolicy(value), value)
+	}
+}
+
+// newRetryContext returns the context of a start command given the retry flags in args
+func newRetryContext(t *testing.T, args ...string) *cli.Context {
+	set := flag.NewFlagSet("start", flag.ContinueOnError)
+	set.Int(FlagRetryInitialInterval, 0, "")
+	set.Float64(FlagRetryBackoff, 0, "")
+	set.Int(FlagRetryMaxInterval, 0, "")
+	set.Int(FlagRetryMaxAttempts, 0, "")
+	set.Int(FlagRetryExpiration, 0, "")
+	set.String(FlagRetryNonRetriableErrors, "", "")
+	require.NoError(t, set.Parse(args))
+	return cli.NewContext(nil, set, nil)
+}
+
+func TestGetRetryPolicy(t *testing.T) {
+	assert.Nil(t, getRetryPolicy(newRetryContext(t)))
+
+	c := newRetryContext(t,
+		"--"+FlagRetryInitialInterval, "5",
+		"--"+FlagRetryBackoff, "1.5",
+		"--"+FlagRetryMaxInterval, "60",
+		"--"+FlagRetryMaxAttempts, "3",
+		"--"+FlagRetryNonRetriableErrors, "bad input, not found")
+	assert.Equal(t, &cadence.RetryPolicy{
+		InitialInterval:          5 * time.Second,
+		BackoffCoefficient:       1.5,
+		MaximumInterval:          time.Minute,
+		MaximumAttempts:          3,
+		NonRetriableErrorReasons: []string{"bad input", "not found"},
+	}, getRetryPolicy(c))
+}
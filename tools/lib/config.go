@@ -0,0 +1,263 @@
+package lib
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/urfave/cli"
+	factory "github.com/venkat1109/cadence-codelab/common"
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	// Config models the cli config file, a set of named contexts
+	// and the one currently in use
+	Config struct {
+		CurrentContext string                 `yaml:"current_context"`
+		Contexts       map[string]*ContextCfg `yaml:"contexts"`
+	}
+
+	// ContextCfg holds the connection defaults for one cadence cluster
+	ContextCfg struct {
+		Address   string  `yaml:"address"`
+		Domain    string  `yaml:"domain"`
+		Transport string  `yaml:"transport,omitempty"`
+		TLS       *TLSCfg `yaml:"tls,omitempty"`
+		Output    string  `yaml:"output,omitempty"`
+	}
+
+	// TLSCfg holds the TLS settings used with the grpc transport
+	TLSCfg struct {
+		CertPath   string `yaml:"cert_path,omitempty"`
+		KeyPath    string `yaml:"key_path,omitempty"`
+		CAPath     string `yaml:"ca_path,omitempty"`
+		ServerName string `yaml:"server_name,omitempty"`
+	}
+)
+
+const (
+	configFileEnv     = "CADENCE_CLI_CONFIG"
+	defaultConfigFile = ".cadence/config.yaml"
+)
+
+// LoadContext applies the selected context from the config file to every global
+// flag that was not set on the command line or through its environment variable
+func LoadContext(c *cli.Context) error {
+	cfg, err := selectedContext(c)
+	if err != nil || cfg == nil {
+		return err
+	}
+
+	defaults := map[string]string{
+		FlagAddress:   cfg.Address,
+		FlagDomain:    cfg.Domain,
+		FlagTransport: cfg.Transport,
+		FlagOutput:    cfg.Output,
+	}
+	for flag, value := range defaults {
+		if len(value) == 0 || c.GlobalIsSet(flag) {
+			continue
+		}
+		if err := c.GlobalSet(flag, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectedContext returns the context chosen with --context or the current one of the
+// config file, nil when none is configured
+func selectedContext(c *cli.Context) (*ContextCfg, error) {
+	config, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	name := config.CurrentContext
+	if c.GlobalIsSet(FlagContext) {
+		name = c.GlobalString(FlagContext)
+	}
+	if len(name) == 0 {
+		return nil, nil
+	}
+
+	cfg, ok := config.Contexts[name]
+	if !ok {
+		return nil, fmt.Errorf("context %s not found in %s", name, configFilePath())
+	}
+	return cfg, nil
+}
+
+// ListContexts lists the contexts in the config file, marking the current one
+func ListContexts(c *cli.Context) {
+	config, err := loadConfig()
+	ExitIfError(err)
+
+	names := make([]string, 0, len(config.Contexts))
+	for name := range config.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cfg := config.Contexts[name]
+		marker := " "
+		if name == config.CurrentContext {
+			marker = "*"
+		}
+		transport := cfg.Transport
+		if len(transport) == 0 {
+			transport = factory.TransportTChannel
+		}
+		fmt.Printf("%s %s, Address:%v, Domain:%v, Transport:%v, TLS:%v, Output:%v\n",
+			marker, name, cfg.Address, cfg.Domain, transport, cfg.TLS != nil, cfg.Output)
+	}
+}
+
+// UseContext switches the current context in the config file
+func UseContext(c *cli.Context) {
+	name := getRequiredArg(c, "context name")
+
+	config, err := loadConfig()
+	ExitIfError(err)
+	if _, ok := config.Contexts[name]; !ok {
+		ExitIfError(fmt.Errorf("context %s not found in %s", name, configFilePath()))
+	}
+
+	config.CurrentContext = name
+	ExitIfError(saveConfig(config))
+	fmt.Printf("Switched to context %s.\n", name)
+}
+
+// AddContext adds or replaces a context in the config file. The first context
+// added becomes the current one.
+func AddContext(c *cli.Context) {
+	name := getRequiredArg(c, "context name")
+
+	cfg := &ContextCfg{
+		Address:   c.String(FlagAddress),
+		Domain:    c.String(FlagDomain),
+		Transport: c.String(FlagTransport),
+		Output:    c.String(FlagOutput),
+	}
+	if c.IsSet(FlagTLSCertPath) || c.IsSet(FlagTLSKeyPath) || c.IsSet(FlagTLSCAPath) || c.IsSet(FlagTLSServerName) {
+		cfg.TLS = &TLSCfg{
+			CertPath:   c.String(FlagTLSCertPath),
+			KeyPath:    c.String(FlagTLSKeyPath),
+			CAPath:     c.String(FlagTLSCAPath),
+			ServerName: c.String(FlagTLSServerName),
+		}
+	}
+	ExitIfError(validateContext(cfg))
+
+	config, err := loadConfig()
+	ExitIfError(err)
+	config.Contexts[name] = cfg
+	if len(config.CurrentContext) == 0 {
+		config.CurrentContext = name
+	}
+
+	ExitIfError(saveConfig(config))
+	fmt.Printf("Context %s saved to %s.\n", name, configFilePath())
+}
+
+func validateContext(cfg *ContextCfg) error {
+	switch cfg.Transport {
+	case "", factory.TransportTChannel, factory.TransportGRPC:
+	default:
+		return fmt.Errorf("invalid transport '%s', must be %s or %s", cfg.Transport, factory.TransportTChannel, factory.TransportGRPC)
+	}
+	if cfg.TLS != nil && cfg.Transport != factory.TransportGRPC {
+		return fmt.Errorf("TLS requires the %s transport", factory.TransportGRPC)
+	}
+	if cfg.TLS != nil && (len(cfg.TLS.CertPath) == 0) != (len(cfg.TLS.KeyPath) == 0) {
+		return errors.New("TLS cert and key paths must be set together")
+	}
+	return validateOutput(cfg.Output)
+}
+
+// contextTLS returns the TLS settings of the context when they apply to the connection, that is
+// when the transport in use is grpc and the address is the one of the context. An address or
+// transport given on the command line does not inherit the TLS settings of the context.
+func contextTLS(cfg *ContextCfg, address string, transport string) *TLSCfg {
+	if cfg == nil || cfg.TLS == nil || len(cfg.Address) == 0 {
+		return nil
+	}
+	if transport != factory.TransportGRPC || address != cfg.Address {
+		return nil
+	}
+	return cfg.TLS
+}
+
+// buildTLSConfig returns the TLS config of the settings, nil when TLS is not configured
+func buildTLSConfig(cfg *TLSCfg) (*tls.Config, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{ServerName: cfg.ServerName}
+	if len(cfg.CertPath) > 0 {
+		cert, err := tls.LoadX509KeyPair(cfg.CertPath, cfg.KeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS key pair: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if len(cfg.CAPath) > 0 {
+		caData, err := ioutil.ReadFile(cfg.CAPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS CA file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caData) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CAPath)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+func configFilePath() string {
+	if path := os.Getenv(configFileEnv); len(path) > 0 {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return defaultConfigFile
+	}
+	return filepath.Join(home, defaultConfigFile)
+}
+
+// loadConfig reads the config file, a missing file is treated as an empty config
+func loadConfig() (*Config, error) {
+	config := &Config{}
+	data, err := ioutil.ReadFile(configFilePath())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", configFilePath(), err)
+	}
+	if config.Contexts == nil {
+		config.Contexts = make(map[string]*ContextCfg)
+	}
+	return config, nil
+}
+
+func saveConfig(config *Config) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	path := configFilePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
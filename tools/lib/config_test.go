@@ -0,0 +1,58 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	factory "github.com/venkat1109/cadence-codelab/common"
+)
+
+func TestValidateContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ContextCfg
+		wantErr bool
+	}{
+		{name: "defaults", cfg: ContextCfg{Address: "127.0.0.1:7933"}},
+		{name: "grpc with TLS", cfg: ContextCfg{Transport: factory.TransportGRPC, TLS: &TLSCfg{CAPath: "ca.pem"}}},
+		{name: "json output", cfg: ContextCfg{Output: OutputJSON}},
+		{name: "invalid transport", cfg: ContextCfg{Transport: "http"}, wantErr: true},
+		{name: "TLS over tchannel", cfg: ContextCfg{TLS: &TLSCfg{CAPath: "ca.pem"}}, wantErr: true},
+		{name: "cert without key", cfg: ContextCfg{Transport: factory.TransportGRPC, TLS: &TLSCfg{CertPath: "cert.pem"}}, wantErr: true},
+		{name: "invalid output", cfg: ContextCfg{Output: "yaml"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateContext(&tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestContextTLS(t *testing.T) {
+	tlsCfg := &TLSCfg{CAPath: "ca.pem"}
+	cfg := &ContextCfg{Address: "cadence.example.com:7833", Transport: factory.TransportGRPC, TLS: tlsCfg}
+
+	tests := []struct {
+		name      string
+		cfg       *ContextCfg
+		address   string
+		transport string
+		want      *TLSCfg
+	}{
+		{name: "context address over grpc", cfg: cfg, address: cfg.Address, transport: factory.TransportGRPC, want: tlsCfg},
+		{name: "no context", address: cfg.Address, transport: factory.TransportGRPC},
+		{name: "address from the command line", cfg: cfg, address: "127.0.0.1:7833", transport: factory.TransportGRPC},
+		{name: "tchannel from the command line", cfg: cfg, address: cfg.Address, transport: factory.TransportTChannel},
+		{name: "context without TLS", cfg: &ContextCfg{Address: cfg.Address}, address: cfg.Address, transport: factory.TransportGRPC},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, contextTLS(tt.cfg, tt.address, tt.transport))
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli"
+	"github.com/venkat1109/cadence-codelab/cron/jobs"
+	"github.com/venkat1109/cadence-codelab/cron/workflow"
+)
+
+// DescribeCron shows the schedule, state and recent runs of a Cron workflow
+func DescribeCron(c *cli.Context) {
+	wfClient := getWorkflowClient(c)
+	wid := cronWorkflowID(c)
+	rid := c.String(FlagRunID)
+
+	ctx, cancel := newContext()
+	defer cancel()
+	value, err := wfClient.QueryWorkflow(ctx, wid, rid, workflow.QueryStatus)
+	ExitIfError(err)
+
+	var status workflow.CronStatus
+	ExitIfError(value.Get(&status))
+	if isJSONOutput(c) {
+		printJSON(status)
+		return
+	}
+	printCronStatus(&status)
+}
+
+// PauseCron pauses a Cron workflow until it is resumed
+func PauseCron(c *cli.Context) {
+	signalCron(c, workflow.SignalPause, getReason(c))
+}
+
+// ResumeCron resumes a paused Cron workflow
+func ResumeCron(c *cli.Context) {
+	signalCron(c, workflow.SignalResume, nil)
+}
+
+// TriggerCron makes a Cron workflow run its jobs now
+func TriggerCron(c *cli.Context) {
+	signalCron(c, workflow.SignalTrigger, nil)
+}
+
+// UpdateCron updates the expression, frequency, time zone or hostgroups of a Cron workflow
+func UpdateCron(c *cli.Context) {
+	update := workflow.ScheduleUpdate{
+		Expression: c.String(FlagCronSchedule),
+		TimeZone:   c.String(FlagTimeZone),
+	}
+	if frequency := c.String(FlagFrequency); len(frequency) > 0 {
+		d, err := time.ParseDuration(frequency)
+		ExitIfError(err)
+		update.Frequency = d
+	}
+	if hostgroups := c.String(FlagHostgroups); len(hostgroups) > 0 {
+		for _, hg := range strings.Split(hostgroups, ",") {
+			if hg = strings.TrimSpace(hg); len(hg) > 0 {
+				update.Hostgroups = append(update.Hostgroups, hg)
+			}
+		}
+	}
+	if len(update.Expression) == 0 && update.Frequency == 0 && len(update.TimeZone) == 0 && len(update.Hostgroups) == 0 {
+		ExitIfError(fmt.Errorf("one of --%s, --%s, --%s or --%s is required", FlagCronSchedule, FlagFrequency, FlagTimeZone, FlagHostgroups))
+	}
+	signalCron(c, workflow.SignalUpdate, update)
+}
+
+// BackfillCron makes a Cron workflow run the fire times of its schedule within a time range
+func BackfillCron(c *cli.Context) {
+	earliest := getRequiredOption(c, FlagEarliestTime)
+	latest := getRequiredOption(c, FlagLatestTime)
+	request := workflow.BackfillRequest{
+		Start:       time.Unix(0, parseTime(earliest, 0)),
+		End:         time.Unix(0, parseTime(latest, 0)),
+		Parallelism: c.Int(FlagParallelism),
+		Overlap:     workflow.OverlapPolicy(c.String(FlagOverlap)),
+	}
+	if request.End.Before(request.Start) {
+		ExitIfError(fmt.Errorf("--%s must not be before --%s", FlagLatestTime, FlagEarliestTime))
+	}
+	signalCron(c, workflow.SignalBackfill, request)
+}
+
+// signalCron sends a control signal to a Cron workflow. The signal is applied
+// asynchronously, describe the workflow to see its effect.
+func signalCron(c *cli.Context, signal string, arg interface{}) {
+	wfClient := getWorkflowClient(c)
+	wid := cronWorkflowID(c)
+	getReason(c)
+	rid := c.String(FlagRunID)
+
+	ctx, cancel := newContext()
+	defer cancel()
+	err := wfClient.SignalWorkflow(ctx, wid, rid, signal, arg)
+	writeAudit(c, wid, rid, err)
+
+	if err != nil {
+		fmt.Printf("Cron %s failed: %v\n", signal, err)
+	} else {
+		fmt.Printf("Cron %s succeed.\n", signal)
+	}
+}
+
+// cronWorkflowID returns the id of the targeted Cron workflow, given as is or by job name
+func cronWorkflowID(c *cli.Context) string {
+	wid := c.String(FlagWorkflowID)
+	job := c.String(FlagJob)
+	switch {
+	case len(wid) > 0 && len(job) > 0:
+		ExitIfError(fmt.Errorf("--%s and --%s are mutually exclusive", FlagWorkflowID, FlagJob))
+	case len(job) > 0:
+		return jobs.WorkflowID(job)
+	case len(wid) == 0:
+		ExitIfError(fmt.Errorf("one of --%s or --%s is required", FlagJob, FlagWorkflowID))
+	}
+	return wid
+}
+
+func printCronStatus(status *workflow.CronStatus) {
+	schedule, state := status.Schedule, status.State
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if len(schedule.Expression) > 0 {
+		fmt.Fprintf(w, "Expression:\t%s\n", schedule.Expression)
+	} else {
+		fmt.Fprintf(w, "Frequency:\t%v\n", schedule.Frequency)
+	}
+	if len(schedule.TimeZone) > 0 {
+		fmt.Fprintf(w, "TimeZone:\t%s\n", schedule.TimeZone)
+	}
+	fmt.Fprintf(w, "Hostgroups:\t%s\n", strings.Join(schedule.Hostgroups, ", "))
+	fmt.Fprintf(w, "Mode:\t%s\n", orDefault(string(schedule.Mode), string(workflow.ModeRolling)))
+	fmt.Fprintf(w, "Overlap:\t%s\n", orDefault(string(schedule.Overlap), string(workflow.OverlapSkip)))
+	if state.Paused {
+		fmt.Fprintf(w, "Paused:\ttrue (%s)\n", state.PauseReason)
+	} else {
+		fmt.Fprintf(w, "Paused:\tfalse\n")
+	}
+	if state.NextFireTime.IsZero() {
+		fmt.Fprintf(w, "NextFireTime:\t-\n")
+	} else {
+		fmt.Fprintf(w, "NextFireTime:\t%s\n", state.NextFireTime.Format(time.RFC3339))
+	}
+	fmt.Fprintf(w, "Running:\t%v\n", state.Running)
+	fmt.Fprintf(w, "Ticks:\t%d\n", state.Ticks)
+	fmt.Fprintf(w, "Runs:\t%d (%d triggered)\n", state.Runs, state.Triggered)
+	fmt.Fprintf(w, "Skipped:\t%d\n", state.SkippedCount)
+	fmt.Fprintf(w, "ConsecutiveFailures:\t%d\n", state.ConsecutiveFailures)
+	if cal := schedule.Calendar; cal != nil {
+		var blackouts []string
+		for _, b := range cal.Blackouts {
+			blackouts = append(blackouts, b.Start+"-"+b.End)
+		}
+		fmt.Fprintf(w, "Calendar:\tblackouts [%s], business days %v, %d holidays, %s\n", strings.Join(blackouts, ", "),
+			cal.BusinessDays, len(cal.Holidays), orDefault(string(cal.Policy), string(workflow.BlackoutSkip)))
+		fmt.Fprintf(w, "BlackedOut:\t%d\n", state.BlackedOut)
+	}
+	fmt.Fprintf(w, "Buffered:\t%d\n", len(state.Buffered))
+	if len(state.UpdateError) > 0 {
+		fmt.Fprintf(w, "UpdateError:\t%s\n", state.UpdateError)
+	}
+	if b := state.Backfill; b != nil {
+		fmt.Fprintf(w, "Backfill:\t%s to %s, %d/%d done, %d running, %d failed, %d queued\n",
+			b.Request.Start.Format(time.RFC3339), b.Request.End.Format(time.RFC3339),
+			b.Succeeded+b.Failed, b.Total, b.Running, b.Failed, len(state.QueuedBackfills))
+	} else if b := state.LastBackfill; b != nil {
+		fmt.Fprintf(w, "LastBackfill:\t%s to %s, %d succeeded, %d failed, canceled %v\n",
+			b.Request.Start.Format(time.RFC3339), b.Request.End.Format(time.RFC3339), b.Succeeded, b.Failed, b.Canceled)
+	}
+	if len(state.BackfillError) > 0 {
+		fmt.Fprintf(w, "BackfillError:\t%s\n", state.BackfillError)
+	}
+	w.Flush()
+
+	if len(state.RecentRuns) == 0 {
+		return
+	}
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FIRE TIME\tBACKFILL\tSUCCEEDED\tFAILED\tABORTED")
+	for i := len(state.RecentRuns) - 1; i >= 0; i-- {
+		run := state.RecentRuns[i]
+		fmt.Fprintf(w, "%s\t%v\t%s\t%s\t%s\n", run.FireTime.Format(time.RFC3339), run.Backfill,
+			strings.Join(run.Succeeded, ","), strings.Join(run.Failed, ","), strings.Join(run.Aborted, ","))
+	}
+	w.Flush()
+}
+
+func orDefault(value, defaultValue string) string {
+	if len(value) == 0 {
+		return defaultValue
+	}
+	return value
+}
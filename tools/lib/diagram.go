@@ -0,0 +1,240 @@
+package lib
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli"
+	"github.com/venkat1109/cadence-codelab/common/timeline"
+)
+
+// Diagram formats supported by the show command
+const (
+	FormatMermaid = "mermaid"
+	FormatDot     = "dot"
+	FormatSVG     = "svg"
+)
+
+const (
+	svgRowHeight  = 24
+	svgLabelWidth = 320
+	svgChartWidth = 800
+)
+
+type (
+	// timelineRow is one task of the flattened task tree, children follow their parent
+	timelineRow struct {
+		task  *timeline.Task
+		depth int
+		start int64
+		end   int64
+	}
+)
+
+// statusColors maps timeline task statuses to the colors used by the dot and svg renderers
+var statusColors = map[string]string{
+	timeline.StatusScheduled: "#dddddd",
+	timeline.StatusRunning:   "#5bc0de",
+	timeline.StatusCompleted: "#5cb85c",
+	timeline.StatusFailed:    "#d9534f",
+	timeline.StatusTimedOut:  "#f0ad4e",
+	timeline.StatusCanceled:  "#999999",
+}
+
+// statusNames maps timeline task statuses to readable names
+var statusNames = map[string]string{
+	timeline.StatusScheduled: "scheduled",
+	timeline.StatusRunning:   "running",
+	timeline.StatusCompleted: "completed",
+	timeline.StatusFailed:    "failed",
+	timeline.StatusTimedOut:  "timed out",
+	timeline.StatusCanceled:  "canceled",
+}
+
+// renderTimeline renders the task tree of a workflow execution, including its child
+// workflows, as a timeline diagram in the given format
+func renderTimeline(c *cli.Context, wid string, rid string, format string) {
+	wfClient := getWorkflowClient(c)
+
+	group, err := timeline.NewTaskGroupExecution(wfClient).Transform(wid, rid)
+	if err != nil {
+		ExitIfError(err)
+	}
+
+	now := time.Now().UnixNano()
+	groupEnd := group.EndTime
+	if groupEnd == 0 {
+		groupEnd = now
+	}
+	rows := flattenTasks(group.Tasks, 0, groupEnd, nil)
+
+	switch format {
+	case FormatMermaid:
+		renderMermaid(os.Stdout, group, rows)
+	case FormatDot:
+		renderDot(os.Stdout, group)
+	case FormatSVG:
+		renderSVG(os.Stdout, group, rows, groupEnd)
+	default:
+		ExitIfError(fmt.Errorf("invalid %s '%s', must be %s, %s or %s", FlagFormat, format, FormatMermaid, FormatDot, FormatSVG))
+	}
+}
+
+// flattenTasks walks the task tree depth first. Tasks that are still open are
+// drawn up to openEnd.
+func flattenTasks(tasks []*timeline.Task, depth int, openEnd int64, rows []timelineRow) []timelineRow {
+	for _, t := range tasks {
+		end := t.EndTime
+		if end == 0 {
+			end = openEnd
+		}
+		rows = append(rows, timelineRow{task: t, depth: depth, start: t.StartTime, end: end})
+		rows = flattenTasks(t.SubTasks, depth+1, end, rows)
+	}
+	return rows
+}
+
+func renderMermaid(w io.Writer, group *timeline.TaskGroup, rows []timelineRow) {
+	fmt.Fprintln(w, "gantt")
+	fmt.Fprintf(w, "    title %s (%s)\n", mermaidText(group.ID), statusName(string(group.Status)))
+	fmt.Fprintln(w, "    dateFormat x")
+	fmt.Fprintln(w, "    axisFormat %H:%M:%S")
+	fmt.Fprintf(w, "    section %s\n", mermaidText(group.ID))
+
+	// sections holds the workflow names from the root down to the current depth
+	sections := []string{group.ID}
+	for i, row := range rows {
+		current := sections[len(sections)-1]
+		if row.depth >= len(sections) {
+			sections = append(sections, rows[i-1].task.Name)
+		}
+		sections = sections[:row.depth+1]
+		if sections[row.depth] != current {
+			fmt.Fprintf(w, "    section %s\n", mermaidText(sections[row.depth]))
+		}
+
+		tags := mermaidTags(string(row.task.Status))
+		fmt.Fprintf(w, "    %s %s %s :%st%d, %d, %d\n",
+			mermaidText(row.task.Name), statusName(string(row.task.Status)), formatDuration(row.end-row.start),
+			tags, i, row.start/int64(time.Millisecond), row.end/int64(time.Millisecond))
+	}
+}
+
+// mermaidTags maps a task status to the gantt tags that give it its color
+func mermaidTags(status string) string {
+	switch status {
+	case timeline.StatusCompleted:
+		return "done, "
+	case timeline.StatusFailed, timeline.StatusTimedOut:
+		return "crit, "
+	case timeline.StatusCanceled:
+		return "crit, done, "
+	}
+	return "active, "
+}
+
+// mermaidText strips the characters that break the gantt task syntax
+func mermaidText(text string) string {
+	return strings.NewReplacer(":", " ", "#", " ", ";", " ").Replace(text)
+}
+
+func renderDot(w io.Writer, group *timeline.TaskGroup) {
+	fmt.Fprintln(w, "digraph timeline {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box, style=filled, fontname=Helvetica];")
+	fmt.Fprintf(w, "  label=%q;\n", fmt.Sprintf("%s (%s)", group.ID, statusName(string(group.Status))))
+	renderDotTasks(w, group.Tasks, "", "  ")
+	fmt.Fprintln(w, "}")
+}
+
+// renderDotTasks chains the tasks in history order, child workflows become clusters
+// holding their own chain
+func renderDotTasks(w io.Writer, tasks []*timeline.Task, prefix string, indent string) {
+	prev := ""
+	for _, t := range tasks {
+		node := fmt.Sprintf("%st%d", prefix, t.ID)
+		label := fmt.Sprintf("%s\\n%s %s", t.Name, statusName(string(t.Status)), formatDuration(taskDuration(t)))
+		fmt.Fprintf(w, "%s%s [label=\"%s\", fillcolor=%q];\n", indent, node, strings.Replace(label, "\"", "'", -1), statusColor(string(t.Status)))
+
+		if len(t.SubTasks) > 0 {
+			fmt.Fprintf(w, "%ssubgraph cluster_%s {\n", indent, node)
+			fmt.Fprintf(w, "%s  label=%q;\n", indent, t.Name)
+			renderDotTasks(w, t.SubTasks, node+"_", indent+"  ")
+			fmt.Fprintf(w, "%s}\n", indent)
+			fmt.Fprintf(w, "%s%s -> %s_t%d [style=dashed];\n", indent, node, node, t.SubTasks[0].ID)
+		}
+
+		if len(prev) > 0 {
+			fmt.Fprintf(w, "%s%s -> %s;\n", indent, prev, node)
+		}
+		prev = node
+	}
+}
+
+func renderSVG(w io.Writer, group *timeline.TaskGroup, rows []timelineRow, groupEnd int64) {
+	start := group.StartTime
+	end := groupEnd
+	for _, row := range rows {
+		if start == 0 || row.start < start {
+			start = row.start
+		}
+		if row.end > end {
+			end = row.end
+		}
+	}
+	span := end - start
+	if span <= 0 {
+		span = 1
+	}
+
+	width := svgLabelWidth + svgChartWidth + 20
+	height := (len(rows) + 2) * svgRowHeight
+	fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" font-family=\"Helvetica\" font-size=\"12\">\n", width, height)
+	fmt.Fprintf(w, "  <text x=\"4\" y=\"16\" font-weight=\"bold\">%s (%s) %s</text>\n",
+		html.EscapeString(group.ID), statusName(string(group.Status)), formatDuration(groupEnd-group.StartTime))
+
+	for i, row := range rows {
+		y := (i + 1) * svgRowHeight
+		x := svgLabelWidth + int(int64(svgChartWidth)*(row.start-start)/span)
+		barWidth := int(int64(svgChartWidth) * (row.end - row.start) / span)
+		if barWidth < 2 {
+			barWidth = 2
+		}
+
+		fmt.Fprintf(w, "  <text x=\"%d\" y=\"%d\">%s</text>\n", 4+row.depth*16, y+16, html.EscapeString(row.task.Name))
+		fmt.Fprintf(w, "  <rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\"><title>%s %s %s</title></rect>\n",
+			x, y+4, barWidth, svgRowHeight-8, statusColor(string(row.task.Status)),
+			html.EscapeString(row.task.Name), statusName(string(row.task.Status)), formatDuration(row.end-row.start))
+		fmt.Fprintf(w, "  <text x=\"%d\" y=\"%d\" fill=\"#333333\">%s</text>\n", x+barWidth+4, y+16, formatDuration(row.end-row.start))
+	}
+	fmt.Fprintln(w, "</svg>")
+}
+
+func taskDuration(t *timeline.Task) int64 {
+	if t.EndTime == 0 {
+		return time.Now().UnixNano() - t.StartTime
+	}
+	return t.EndTime - t.StartTime
+}
+
+func statusColor(status string) string {
+	if color, ok := statusColors[status]; ok {
+		return color
+	}
+	return statusColors[timeline.StatusScheduled]
+}
+
+func statusName(status string) string {
+	if name, ok := statusNames[status]; ok {
+		return name
+	}
+	return string(status)
+}
+
+func formatDuration(nanos int64) string {
+	return time.Duration(nanos).Round(time.Millisecond).String()
+}
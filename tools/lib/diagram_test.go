@@ -0,0 +1,71 @@
+package lib
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/venkat1109/cadence-codelab/common/timeline"
+)
+
+// testTimeline returns a workflow with a completed activity and a running child
+// workflow whose activity failed
+func testTimeline() *timeline.TaskGroup {
+	ms := int64(time.Millisecond)
+	return &timeline.TaskGroup{
+		ID:     "wf:1",
+		Status: timeline.StatusCompleted,
+		Tasks: []*timeline.Task{
+			{ID: 5, Name: "activity a", Status: timeline.StatusCompleted, StartTime: 1000 * ms, EndTime: 3000 * ms},
+			{
+				ID: 8, Name: "child", Status: timeline.StatusRunning, StartTime: 2000 * ms,
+				SubTasks: []*timeline.Task{{ID: 5, Name: "b", Status: timeline.StatusFailed, StartTime: 2500 * ms}},
+			},
+		},
+	}
+}
+
+func TestFlattenTasks(t *testing.T) {
+	ms := int64(time.Millisecond)
+	group := testTimeline()
+	rows := flattenTasks(group.Tasks, 0, 5000*ms, nil)
+	require.Len(t, rows, 3)
+
+	want := []struct {
+		name       string
+		depth      int
+		start, end int64
+	}{
+		{name: "activity a", depth: 0, start: 1000 * ms, end: 3000 * ms},
+		{name: "child", depth: 0, start: 2000 * ms, end: 5000 * ms},
+		// open tasks of a child end with their parent
+		{name: "b", depth: 1, start: 2500 * ms, end: 5000 * ms},
+	}
+	for i, w := range want {
+		assert.Equal(t, w.name, rows[i].task.Name)
+		assert.Equal(t, w.depth, rows[i].depth)
+		assert.Equal(t, w.start, rows[i].start)
+		assert.Equal(t, w.end, rows[i].end)
+	}
+}
+
+func TestRenderMermaid(t *testing.T) {
+	group := testTimeline()
+	var out bytes.Buffer
+	renderMermaid(&out, group, flattenTasks(group.Tasks, 0, 5000*int64(time.Millisecond), nil))
+
+	assert.Equal(t, []string{
+		"gantt",
+		"    title wf 1 (completed)",
+		"    dateFormat x",
+		"    axisFormat %H:%M:%S",
+		"    section wf 1",
+		"    activity a completed 2s :done, t0, 1000, 3000",
+		"    child running 3s :active, t1, 2000, 5000",
+		"    section child",
+		"    b failed 2.5s :crit, t2, 2500, 5000",
+	}, strings.Split(strings.TrimSpace(out.String()), "\n"))
+}
@@ -0,0 +1,316 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli"
+	s "go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/client"
+)
+
+// diffTimingThreshold is the timing delta above which an aligned event is highlighted
+const diffTimingThreshold = time.Second
+
+// Diff row statuses
+const (
+	diffSame    = "same"
+	diffChanged = "changed"
+	diffOnlyInA = "only_a"
+	diffOnlyInB = "only_b"
+)
+
+// diffMarks are the markers printed in front of each row of the text output
+var diffMarks = map[string]string{
+	diffSame:    " ",
+	diffChanged: "!",
+	diffOnlyInA: "<",
+	diffOnlyInB: ">",
+}
+
+// diffAttributes are the event attributes compared between aligned events, ids and
+// identities always differ between executions and are left out
+var diffAttributes = []string{
+	"activityType", "workflowType", "signalName", "markerName", "timerId",
+	"input", "result", "details", "reason", "cause", "timeoutType", "heartbeatDetails",
+	"startToFireTimeoutSeconds", "scheduleToCloseTimeoutSeconds", "startToCloseTimeoutSeconds",
+}
+
+// decisionTaskEvents are bookkeeping events skipped when aligning histories
+var decisionTaskEvents = map[s.EventType]bool{
+	s.EventTypeDecisionTaskScheduled: true,
+	s.EventTypeDecisionTaskStarted:   true,
+	s.EventTypeDecisionTaskCompleted: true,
+}
+
+type (
+	// historyStep is a history event keyed by its type and the activity, timer,
+	// child workflow or signal it belongs to
+	historyStep struct {
+		key    string
+		event  *historyEvent
+		offset time.Duration
+	}
+
+	// diffRow is one aligned pair of events, A or B is nil when the event only
+	// exists in one of the histories
+	diffRow struct {
+		Status      string                    `json:"status"`
+		Key         string                    `json:"key"`
+		A           *historyEvent             `json:"a,omitempty"`
+		B           *historyEvent             `json:"b,omitempty"`
+		OffsetA     time.Duration             `json:"offsetA,omitempty"`
+		OffsetB     time.Duration             `json:"offsetB,omitempty"`
+		Delta       time.Duration             `json:"delta,omitempty"`
+		Differences map[string][2]interface{} `json:"differences,omitempty"`
+	}
+)
+
+// DiffHistory aligns the histories of two workflow executions and shows the events
+// that only exist in one of them, the aligned events whose inputs, results or
+// failures differ, and the timing delta of every aligned event
+func DiffHistory(c *cli.Context) {
+	wfClient := getWorkflowClient(c)
+
+	widA := getRequiredOption(c, FlagWorkflowID)
+	widB := getRequiredOption(c, FlagWorkflowID2)
+
+	stepsA := historySteps(readHistory(wfClient, widA, c.String(FlagRunID)))
+	stepsB := historySteps(readHistory(wfClient, widB, c.String(FlagRunID2)))
+	rows := alignSteps(stepsA, stepsB)
+
+	if isJSONOutput(c) {
+		printJSON(rows)
+		return
+	}
+	printDiff(rows, widA, widB)
+}
+
+func readHistory(wfClient client.Client, wid string, rid string) []*s.HistoryEvent {
+	ctx, cancel := newContext()
+	defer cancel()
+
+	var events []*s.HistoryEvent
+	iter := wfClient.GetWorkflowHistory(ctx, wid, rid, false, s.HistoryEventFilterTypeAllEvent)
+	for iter.HasNext() {
+		e, err := iter.Next()
+		if err != nil {
+			ExitIfError(fmt.Errorf("failed to read history of %s: %v", wid, err))
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+// historySteps keys every event, repeated keys are numbered so that retries and
+// reused timer ids still line up in order
+func historySteps(events []*s.HistoryEvent) []historyStep {
+	if len(events) == 0 {
+		return nil
+	}
+
+	start := events[0].GetTimestamp()
+	identities := make(map[int64]string)
+	occurrences := make(map[string]int)
+
+	var steps []historyStep
+	for _, e := range events {
+		if decisionTaskEvents[e.GetEventType()] {
+			continue
+		}
+		key := fmt.Sprintf("%v(%s)", e.GetEventType(), eventIdentity(e, identities))
+		occurrences[key]++
+		if n := occurrences[key]; n > 1 {
+			key = fmt.Sprintf("%s#%d", key, n)
+		}
+		steps = append(steps, historyStep{
+			key:    key,
+			event:  decodeHistoryEvent(e),
+			offset: time.Duration(e.GetTimestamp() - start),
+		})
+	}
+	return steps
+}
+
+// eventIdentity returns the activity id, timer id, child workflow type, signal or marker
+// name the event belongs to. Initiating events record their identity for the events
+// that refer back to them.
+func eventIdentity(e *s.HistoryEvent, identities map[int64]string) string {
+	switch e.GetEventType() {
+	case s.EventTypeActivityTaskScheduled:
+		attr := e.GetActivityTaskScheduledEventAttributes()
+		identities[e.GetEventId()] = attr.GetActivityId()
+		return attr.GetActivityId()
+	case s.EventTypeActivityTaskStarted:
+		return identities[e.GetActivityTaskStartedEventAttributes().GetScheduledEventId()]
+	case s.EventTypeActivityTaskCompleted:
+		return identities[e.GetActivityTaskCompletedEventAttributes().GetScheduledEventId()]
+	case s.EventTypeActivityTaskFailed:
+		return identities[e.GetActivityTaskFailedEventAttributes().GetScheduledEventId()]
+	case s.EventTypeActivityTaskTimedOut:
+		return identities[e.GetActivityTaskTimedOutEventAttributes().GetScheduledEventId()]
+	case s.EventTypeActivityTaskCanceled:
+		return identities[e.GetActivityTaskCanceledEventAttributes().GetScheduledEventId()]
+	case s.EventTypeActivityTaskCancelRequested:
+		return e.GetActivityTaskCancelRequestedEventAttributes().GetActivityId()
+	case s.EventTypeTimerStarted:
+		return e.GetTimerStartedEventAttributes().GetTimerId()
+	case s.EventTypeTimerFired:
+		return e.GetTimerFiredEventAttributes().GetTimerId()
+	case s.EventTypeTimerCanceled:
+		return e.GetTimerCanceledEventAttributes().GetTimerId()
+	case s.EventTypeStartChildWorkflowExecutionInitiated:
+		name := e.GetStartChildWorkflowExecutionInitiatedEventAttributes().GetWorkflowType().GetName()
+		identities[e.GetEventId()] = name
+		return name
+	case s.EventTypeStartChildWorkflowExecutionFailed:
+		return identities[e.GetStartChildWorkflowExecutionFailedEventAttributes().GetInitiatedEventId()]
+	case s.EventTypeChildWorkflowExecutionStarted:
+		return identities[e.GetChildWorkflowExecutionStartedEventAttributes().GetInitiatedEventId()]
+	case s.EventTypeChildWorkflowExecutionCompleted:
+		return identities[e.GetChildWorkflowExecutionCompletedEventAttributes().GetInitiatedEventId()]
+	case s.EventTypeChildWorkflowExecutionFailed:
+		return identities[e.GetChildWorkflowExecutionFailedEventAttributes().GetInitiatedEventId()]
+	case s.EventTypeChildWorkflowExecutionTimedOut:
+		return identities[e.GetChildWorkflowExecutionTimedOutEventAttributes().GetInitiatedEventId()]
+	case s.EventTypeChildWorkflowExecutionCanceled:
+		return identities[e.GetChildWorkflowExecutionCanceledEventAttributes().GetInitiatedEventId()]
+	case s.EventTypeChildWorkflowExecutionTerminated:
+		return identities[e.GetChildWorkflowExecutionTerminatedEventAttributes().GetInitiatedEventId()]
+	case s.EventTypeWorkflowExecutionSignaled:
+		return e.GetWorkflowExecutionSignaledEventAttributes().GetSignalName()
+	case s.EventTypeMarkerRecorded:
+		return e.GetMarkerRecordedEventAttributes().GetMarkerName()
+	}
+	return ""
+}
+
+// alignSteps aligns the two step sequences on their longest common subsequence of keys
+func alignSteps(a []historyStep, b []historyStep) []*diffRow {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i].key == b[j].key {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var rows []*diffRow
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i].key == b[j].key:
+			rows = append(rows, alignedRow(a[i], b[j]))
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			rows = append(rows, &diffRow{Status: diffOnlyInA, Key: a[i].key, A: a[i].event, OffsetA: a[i].offset})
+			i++
+		default:
+			rows = append(rows, &diffRow{Status: diffOnlyInB, Key: b[j].key, B: b[j].event, OffsetB: b[j].offset})
+			j++
+		}
+	}
+	return rows
+}
+
+func alignedRow(a historyStep, b historyStep) *diffRow {
+	row := &diffRow{
+		Status:  diffSame,
+		Key:     a.key,
+		A:       a.event,
+		B:       b.event,
+		OffsetA: a.offset,
+		OffsetB: b.offset,
+		Delta:   b.offset - a.offset,
+	}
+	for _, name := range diffAttributes {
+		valueA, valueB := a.event.Attributes[name], b.event.Attributes[name]
+		if reflect.DeepEqual(valueA, valueB) {
+			continue
+		}
+		if row.Differences == nil {
+			row.Differences = make(map[string][2]interface{})
+		}
+		row.Differences[name] = [2]interface{}{valueA, valueB}
+		row.Status = diffChanged
+	}
+	return row
+}
+
+func printDiff(rows []*diffRow, widA string, widB string) {
+	fmt.Printf("< %s\n> %s\n\n", widA, widB)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, " \tEvent\tIdA\tIdB\tOffsetA\tOffsetB\tDelta")
+	for _, row := range rows {
+		idA, idB, offsetA, offsetB, delta := "-", "-", "-", "-", "-"
+		if row.A != nil {
+			idA = fmt.Sprint(row.A.EventID)
+			offsetA = formatDuration(int64(row.OffsetA))
+		}
+		if row.B != nil {
+			idB = fmt.Sprint(row.B.EventID)
+			offsetB = formatDuration(int64(row.OffsetB))
+		}
+		if row.A != nil && row.B != nil {
+			delta = formatDuration(int64(row.Delta))
+			if row.Delta > diffTimingThreshold || row.Delta < -diffTimingThreshold {
+				delta += " *"
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", diffMarks[row.Status], row.Key, idA, idB, offsetA, offsetB, delta)
+	}
+	w.Flush()
+
+	first := true
+	for _, row := range rows {
+		if row.Status == diffSame {
+			continue
+		}
+		if first {
+			fmt.Printf("\nFirst divergence: %s %s\n", diffMarks[row.Status], row.Key)
+			first = false
+		}
+		if row.Status != diffChanged {
+			continue
+		}
+		fmt.Printf("\n! %s\n", row.Key)
+		for _, name := range diffAttributes {
+			values, ok := row.Differences[name]
+			if !ok {
+				continue
+			}
+			fmt.Printf("  %s\n    < %s\n    > %s\n", name, diffValue(values[0]), diffValue(values[1]))
+		}
+	}
+	if first {
+		fmt.Println("\nNo divergence, the histories only differ in timing.")
+	}
+}
+
+// diffValue renders an attribute value as indented json aligned under its marker
+func diffValue(value interface{}) string {
+	if value == nil {
+		return "<unset>"
+	}
+	data, err := json.MarshalIndent(value, "      ", "  ")
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSpace(string(data))
+}
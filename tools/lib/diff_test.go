@@ -0,0 +1,66 @@
+package lib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	s "go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/common"
+)
+
+func TestHistorySteps(t *testing.T) {
+	events := []*s.HistoryEvent{
+		{EventId: common.Int64Ptr(1), EventType: s.EventTypeWorkflowExecutionStarted.Ptr()},
+		{EventId: common.Int64Ptr(2), EventType: s.EventTypeDecisionTaskScheduled.Ptr()},
+	}
+	events = append(events, activityEvents(5, "1", "placeOrder", s.EventTypeActivityTaskFailed)...)
+	// the activity id is reused by the next attempt
+	events = append(events, activityEvents(8, "1", "placeOrder", s.EventTypeActivityTaskCompleted)...)
+	for i, e := range events {
+		e.Timestamp = common.Int64Ptr(int64(i) * int64(time.Second))
+	}
+
+	steps := historySteps(events)
+	var keys []string
+	for _, step := range steps {
+		keys = append(keys, step.key)
+	}
+	assert.Equal(t, []string{
+		"WorkflowExecutionStarted()",
+		"ActivityTaskScheduled(1)",
+		"ActivityTaskStarted(1)",
+		"ActivityTaskFailed(1)",
+		"ActivityTaskScheduled(1)#2",
+		"ActivityTaskStarted(1)#2",
+		"ActivityTaskCompleted(1)",
+	}, keys)
+	assert.Equal(t, 7*time.Second, steps[len(steps)-1].offset)
+}
+
+func TestAlignSteps(t *testing.T) {
+	step := func(key string, offset time.Duration, result interface{}) historyStep {
+		return historyStep{key: key, offset: offset, event: &historyEvent{Attributes: map[string]interface{}{"result": result}}}
+	}
+	a := []historyStep{step("x", 0, nil), step("y", time.Second, nil), step("z", 2*time.Second, "ok")}
+	b := []historyStep{step("x", 0, nil), step("z", 5*time.Second, "late"), step("w", 6*time.Second, nil)}
+
+	rows := alignSteps(a, b)
+	require.Len(t, rows, 4)
+	want := []struct {
+		status string
+		key    string
+	}{
+		{diffSame, "x"},
+		{diffOnlyInA, "y"},
+		{diffChanged, "z"},
+		{diffOnlyInB, "w"},
+	}
+	for i, w := range want {
+		assert.Equal(t, w.status, rows[i].Status, w.key)
+		assert.Equal(t, w.key, rows[i].Key)
+	}
+	assert.Equal(t, 3*time.Second, rows[2].Delta)
+	assert.Equal(t, [2]interface{}{"ok", "late"}, rows[2].Differences["result"])
+}
@@ -0,0 +1,202 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	s "go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/encoded"
+)
+
+// maxPayloadValues bounds the number of values decoded from a single payload
+const maxPayloadValues = 32
+
+// dataConverter encodes workflow inputs and decodes history payloads, it must
+// match the data converter used by the workers
+var dataConverter = encoded.GetDefaultDataConverter()
+
+type (
+	// historyEvent is a history event with its payloads decoded
+	historyEvent struct {
+		EventID    int64                  `json:"eventId"`
+		Timestamp  int64                  `json:"timestamp"`
+		EventType  string                 `json:"eventType"`
+		Attributes map[string]interface{} `json:"attributes,omitempty"`
+	}
+
+	// historyFilter selects the history events printed by the show command
+	historyFilter struct {
+		eventTypes map[s.EventType]bool
+		activity   string
+		// scheduledIDs holds the scheduled event ids of the activities matching activity
+		scheduledIDs map[int64]bool
+	}
+)
+
+// newHistoryFilter parses the comma separated event types and the activity id or type
+// to filter on, an empty value disables the corresponding filter
+func newHistoryFilter(eventTypes string, activity string) *historyFilter {
+	filter := &historyFilter{activity: activity, scheduledIDs: make(map[int64]bool)}
+	if len(eventTypes) == 0 {
+		return filter
+	}
+
+	filter.eventTypes = make(map[s.EventType]bool)
+	for _, name := range strings.Split(eventTypes, ",") {
+		var eventType s.EventType
+		if err := eventType.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+			ExitIfError(fmt.Errorf("invalid %s '%s'", FlagEventTypes, name))
+		}
+		filter.eventTypes[eventType] = true
+	}
+	return filter
+}
+
+// matches must be called for every event in history order, the activity filter
+// follows activities from their scheduled event
+func (f *historyFilter) matches(e *s.HistoryEvent) bool {
+	if len(f.activity) > 0 && !f.matchesActivity(e) {
+		return false
+	}
+	return f.eventTypes == nil || f.eventTypes[e.GetEventType()]
+}
+
+func (f *historyFilter) matchesActivity(e *s.HistoryEvent) bool {
+	switch e.GetEventType() {
+	case s.EventTypeActivityTaskScheduled:
+		attr := e.GetActivityTaskScheduledEventAttributes()
+		if attr.GetActivityId() == f.activity || attr.GetActivityType().GetName() == f.activity {
+			f.scheduledIDs[e.GetEventId()] = true
+			return true
+		}
+		return false
+	case s.EventTypeActivityTaskStarted:
+		return f.scheduledIDs[e.GetActivityTaskStartedEventAttributes().GetScheduledEventId()]
+	case s.EventTypeActivityTaskCompleted:
+		return f.scheduledIDs[e.GetActivityTaskCompletedEventAttributes().GetScheduledEventId()]
+	case s.EventTypeActivityTaskFailed:
+		return f.scheduledIDs[e.GetActivityTaskFailedEventAttributes().GetScheduledEventId()]
+	case s.EventTypeActivityTaskTimedOut:
+		return f.scheduledIDs[e.GetActivityTaskTimedOutEventAttributes().GetScheduledEventId()]
+	case s.EventTypeActivityTaskCanceled:
+		return f.scheduledIDs[e.GetActivityTaskCanceledEventAttributes().GetScheduledEventId()]
+	case s.EventTypeActivityTaskCancelRequested:
+		return e.GetActivityTaskCancelRequestedEventAttributes().GetActivityId() == f.activity
+	case s.EventTypeRequestCancelActivityTaskFailed:
+		return e.GetRequestCancelActivityTaskFailedEventAttributes().GetActivityId() == f.activity
+	}
+	return false
+}
+
+// printHistoryEvent prints one event with its attributes as indented json
+func printHistoryEvent(e *s.HistoryEvent, printRawTime bool, jsonOutput bool) {
+	event := decodeHistoryEvent(e)
+	if jsonOutput {
+		printJSON(event)
+		return
+	}
+
+	if printRawTime {
+		fmt.Printf("%d, %d, %s\n", event.EventID, event.Timestamp, event.EventType)
+	} else {
+		fmt.Printf("%d, %s, %s\n", event.EventID, convertTime(event.Timestamp), event.EventType)
+	}
+	if len(event.Attributes) == 0 {
+		return
+	}
+	data, err := json.MarshalIndent(event.Attributes, "  ", "  ")
+	if err != nil {
+		ExitIfError(err)
+	}
+	fmt.Printf("  %s\n", data)
+}
+
+// decodeHistoryEvent converts an event to its json form, replacing the raw bytes of
+// inputs, results, signal payloads and failure details with their decoded values
+func decodeHistoryEvent(e *s.HistoryEvent) *historyEvent {
+	event := &historyEvent{
+		EventID:   e.GetEventId(),
+		Timestamp: e.GetTimestamp(),
+		EventType: e.GetEventType().String(),
+	}
+
+	attr := eventAttributes(e)
+	if !attr.IsValid() {
+		return event
+	}
+
+	data, err := json.Marshal(attr.Interface())
+	if err != nil {
+		ExitIfError(err)
+	}
+	if err := json.Unmarshal(data, &event.Attributes); err != nil {
+		ExitIfError(err)
+	}
+
+	attrType := attr.Elem().Type()
+	for i := 0; i < attrType.NumField(); i++ {
+		field := attrType.Field(i)
+		payload, ok := attr.Elem().Field(i).Interface().([]byte)
+		if !ok || payload == nil {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		event.Attributes[name] = decodePayload(payload)
+	}
+	return event
+}
+
+// eventAttributes returns the attributes struct set on the event, the invalid value if none is
+func eventAttributes(e *s.HistoryEvent) reflect.Value {
+	v := reflect.ValueOf(e).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if strings.HasSuffix(v.Type().Field(i).Name, "EventAttributes") && field.Kind() == reflect.Ptr && !field.IsNil() {
+			return field
+		}
+	}
+	return reflect.Value{}
+}
+
+// decodePayload decodes as many values as the data converter finds in the payload. A
+// single value is returned as is, several values as a list. Payloads the converter
+// cannot decode are shown as text, or as raw bytes when they are not valid utf8.
+func decodePayload(payload []byte) interface{} {
+	var values []interface{}
+	for n := 1; n <= maxPayloadValues; n++ {
+		decoded := make([]interface{}, n)
+		ptrs := make([]interface{}, n)
+		for i := range decoded {
+			ptrs[i] = &decoded[i]
+		}
+		if err := dataConverter.FromData(payload, ptrs...); err != nil {
+			break
+		}
+		values = decoded
+	}
+
+	switch {
+	case len(values) == 1:
+		return values[0]
+	case len(values) > 1:
+		return values
+	case utf8.Valid(payload):
+		return string(payload)
+	}
+	return payload
+}
+
+// formatPayload returns the decoded payload as compact json, for printing on one line
+func formatPayload(payload []byte) string {
+	if len(payload) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(decodePayload(payload))
+	if err != nil {
+		return strings.TrimSpace(string(payload))
+	}
+	return string(data)
+}
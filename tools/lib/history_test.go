@@ -0,0 +1,110 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	s "go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/common"
+)
+
+func encodePayload(t *testing.T, values ...interface{}) []byte {
+	payload, err := dataConverter.ToData(values...)
+	require.NoError(t, err)
+	return payload
+}
+
+func TestFormatPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload []byte
+		want    string
+	}{
+		{name: "empty", payload: nil, want: ""},
+		{name: "object", payload: encodePayload(t, map[string]interface{}{"order": "o-1", "items": 2}), want: `{"items":2,"order":"o-1"}`},
+		{name: "several values", payload: encodePayload(t, "done", 3), want: `["done",3]`},
+		{name: "plain text", payload: []byte("not json"), want: `"not json"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatPayload(tt.payload))
+		})
+	}
+}
+
+// activityEvents returns the scheduled, started and closing events of an activity
+func activityEvents(scheduledID int64, activityID string, activityType string, closeType s.EventType) []*s.HistoryEvent {
+	scheduled := &s.HistoryEvent{
+		EventId:   common.Int64Ptr(scheduledID),
+		EventType: s.EventTypeActivityTaskScheduled.Ptr(),
+		ActivityTaskScheduledEventAttributes: &s.ActivityTaskScheduledEventAttributes{
+			ActivityId:   common.StringPtr(activityID),
+			ActivityType: &s.ActivityType{Name: common.StringPtr(activityType)},
+		},
+	}
+	started := &s.HistoryEvent{
+		EventId:                            common.Int64Ptr(scheduledID + 1),
+		EventType:                          s.EventTypeActivityTaskStarted.Ptr(),
+		ActivityTaskStartedEventAttributes: &s.ActivityTaskStartedEventAttributes{ScheduledEventId: common.Int64Ptr(scheduledID)},
+	}
+	closed := &s.HistoryEvent{EventId: common.Int64Ptr(scheduledID + 2), EventType: closeType.Ptr()}
+	switch closeType {
+	case s.EventTypeActivityTaskCompleted:
+		closed.ActivityTaskCompletedEventAttributes = &s.ActivityTaskCompletedEventAttributes{ScheduledEventId: common.Int64Ptr(scheduledID)}
+	case s.EventTypeActivityTaskFailed:
+		closed.ActivityTaskFailedEventAttributes = &s.ActivityTaskFailedEventAttributes{ScheduledEventId: common.Int64Ptr(scheduledID)}
+	}
+	return []*s.HistoryEvent{scheduled, started, closed}
+}
+
+func TestHistoryFilter(t *testing.T) {
+	var events []*s.HistoryEvent
+	events = append(events, &s.HistoryEvent{EventId: common.Int64Ptr(1), EventType: s.EventTypeWorkflowExecutionStarted.Ptr()})
+	events = append(events, activityEvents(5, "1", "placeOrder", s.EventTypeActivityTaskCompleted)...)
+	events = append(events, activityEvents(8, "2", "pickupOrder", s.EventTypeActivityTaskFailed)...)
+
+	tests := []struct {
+		name       string
+		eventTypes string
+		activity   string
+		want       []int64
+	}{
+		{name: "no filter", want: []int64{1, 5, 6, 7, 8, 9, 10}},
+		{name: "activity type", activity: "placeOrder", want: []int64{5, 6, 7}},
+		{name: "activity id", activity: "2", want: []int64{8, 9, 10}},
+		{name: "event types", eventTypes: "ActivityTaskStarted, ActivityTaskFailed", want: []int64{6, 9, 10}},
+		{name: "event type of an activity", eventTypes: "ActivityTaskFailed", activity: "placeOrder"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := newHistoryFilter(tt.eventTypes, tt.activity)
+			var matched []int64
+			for _, e := range events {
+				if filter.matches(e) {
+					matched = append(matched, e.GetEventId())
+				}
+			}
+			assert.Equal(t, tt.want, matched)
+		})
+	}
+}
+
+func TestDecodeHistoryEvent(t *testing.T) {
+	e := &s.HistoryEvent{
+		EventId:   common.Int64Ptr(3),
+		Timestamp: common.Int64Ptr(42),
+		EventType: s.EventTypeWorkflowExecutionSignaled.Ptr(),
+		WorkflowExecutionSignaledEventAttributes: &s.WorkflowExecutionSignaledEventAttributes{
+			SignalName: common.StringPtr("ready"),
+			Input:      encodePayload(t, map[string]interface{}{"order": "o-1"}),
+		},
+	}
+
+	event := decodeHistoryEvent(e)
+	assert.Equal(t, int64(3), event.EventID)
+	assert.Equal(t, int64(42), event.Timestamp)
+	assert.Equal(t, "WorkflowExecutionSignaled", event.EventType)
+	assert.Equal(t, "ready", event.Attributes["signalName"])
+	assert.Equal(t, map[string]interface{}{"order": "o-1"}, event.Attributes["input"])
+}
@@ -0,0 +1,233 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli"
+	s "go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/client"
+)
+
+type (
+	// WorkflowStats summarizes closed workflow executions
+	WorkflowStats struct {
+		Total             int                       `json:"total"`
+		ByCloseStatus     map[string]int            `json:"byCloseStatus"`
+		Duration          LatencyStats              `json:"duration"`
+		Activities        map[string]*ActivityStats `json:"activities"`
+		HistoriesRead     int                       `json:"historiesRead"`
+		HistoryErrors     int                       `json:"historyErrors"`
+		durations         []time.Duration
+		activityLatencies map[string][]time.Duration
+	}
+
+	// ActivityStats summarizes the executions of one activity type
+	ActivityStats struct {
+		Count       int          `json:"count"`
+		Failed      int          `json:"failed"`
+		TimedOut    int          `json:"timedOut"`
+		FailureRate float64      `json:"failureRate"`
+		Latency     LatencyStats `json:"latency"`
+	}
+
+	// LatencyStats holds latency percentiles
+	LatencyStats struct {
+		P50 time.Duration `json:"p50"`
+		P90 time.Duration `json:"p90"`
+		P99 time.Duration `json:"p99"`
+		Max time.Duration `json:"max"`
+	}
+
+	// scheduledActivity tracks an activity between its scheduled and close events
+	scheduledActivity struct {
+		activityType string
+		scheduled    int64
+	}
+)
+
+// WorkflowStatistics scans closed workflow executions and reports close status counts,
+// duration percentiles and per activity type latency and failure rates
+func WorkflowStatistics(c *cli.Context) {
+	wfClient := getWorkflowClient(c)
+
+	earliestTime := parseTime(c.String(FlagEarliestTime), time.Now().Add(-24*time.Hour).UnixNano())
+	latestTime := parseTime(c.String(FlagLatestTime), time.Now().UnixNano())
+	workflowType := c.String(FlagWorkflowType)
+	limit := c.Int(FlagLimit)
+	skipHistory := c.Bool(FlagSkipHistory)
+
+	stats := &WorkflowStats{
+		ByCloseStatus:     make(map[string]int),
+		Activities:        make(map[string]*ActivityStats),
+		activityLatencies: make(map[string][]time.Duration),
+	}
+
+	var nextPageToken []byte
+	for {
+		var result []*s.WorkflowExecutionInfo
+		result, nextPageToken = queryClosedWorkflow(wfClient, 100, earliestTime, latestTime, "", workflowType, nextPageToken)
+		for _, e := range result {
+			stats.Total++
+			stats.ByCloseStatus[e.GetCloseStatus().String()]++
+			stats.durations = append(stats.durations, time.Duration(e.GetCloseTime()-e.GetStartTime()))
+
+			if !skipHistory && stats.HistoriesRead+stats.HistoryErrors < limit {
+				if err := stats.addHistory(wfClient, e.GetExecution()); err != nil {
+					fmt.Fprintf(os.Stderr, "failed to read history of %s: %v\n", e.GetExecution().GetWorkflowId(), err)
+					stats.HistoryErrors++
+				} else {
+					stats.HistoriesRead++
+				}
+			}
+		}
+
+		if len(nextPageToken) == 0 {
+			break
+		}
+	}
+
+	stats.Duration = latencyStats(stats.durations)
+	for activityType, a := range stats.Activities {
+		a.Latency = latencyStats(stats.activityLatencies[activityType])
+		if a.Count > 0 {
+			a.FailureRate = float64(a.Failed+a.TimedOut) / float64(a.Count)
+		}
+	}
+
+	if isJSONOutput(c) {
+		printJSON(stats)
+		return
+	}
+	printStats(stats, earliestTime, latestTime)
+}
+
+// addHistory folds the activity latencies and outcomes of one execution into the stats
+func (stats *WorkflowStats) addHistory(wfClient client.Client, execution *s.WorkflowExecution) error {
+	ctx, cancel := newContext()
+	defer cancel()
+
+	scheduled := make(map[int64]scheduledActivity)
+	iter := wfClient.GetWorkflowHistory(ctx, execution.GetWorkflowId(), execution.GetRunId(), false, s.HistoryEventFilterTypeAllEvent)
+	for iter.HasNext() {
+		event, err := iter.Next()
+		if err != nil {
+			return err
+		}
+
+		var scheduledID int64
+		switch event.GetEventType() {
+		case s.EventTypeActivityTaskScheduled:
+			attr := event.GetActivityTaskScheduledEventAttributes()
+			scheduled[event.GetEventId()] = scheduledActivity{
+				activityType: attr.GetActivityType().GetName(),
+				scheduled:    event.GetTimestamp(),
+			}
+			continue
+		case s.EventTypeActivityTaskCompleted:
+			scheduledID = event.GetActivityTaskCompletedEventAttributes().GetScheduledEventId()
+		case s.EventTypeActivityTaskFailed:
+			scheduledID = event.GetActivityTaskFailedEventAttributes().GetScheduledEventId()
+		case s.EventTypeActivityTaskTimedOut:
+			scheduledID = event.GetActivityTaskTimedOutEventAttributes().GetScheduledEventId()
+		case s.EventTypeActivityTaskCanceled:
+			scheduledID = event.GetActivityTaskCanceledEventAttributes().GetScheduledEventId()
+		default:
+			continue
+		}
+
+		activity, ok := scheduled[scheduledID]
+		if !ok {
+			continue
+		}
+		a, ok := stats.Activities[activity.activityType]
+		if !ok {
+			a = &ActivityStats{}
+			stats.Activities[activity.activityType] = a
+		}
+		a.Count++
+		switch event.GetEventType() {
+		case s.EventTypeActivityTaskFailed:
+			a.Failed++
+		case s.EventTypeActivityTaskTimedOut:
+			a.TimedOut++
+		}
+		latency := time.Duration(event.GetTimestamp() - activity.scheduled)
+		stats.activityLatencies[activity.activityType] = append(stats.activityLatencies[activity.activityType], latency)
+	}
+	return nil
+}
+
+func printStats(stats *WorkflowStats, earliestTime, latestTime int64) {
+	fmt.Printf("Closed workflows started between %s and %s: %d\n", convertTime(earliestTime), convertTime(latestTime), stats.Total)
+	if stats.Total == 0 {
+		return
+	}
+
+	statuses := make([]string, 0, len(stats.ByCloseStatus))
+	for status := range stats.ByCloseStatus {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nCloseStatus\tCount\tPercent")
+	for _, status := range statuses {
+		count := stats.ByCloseStatus[status]
+		fmt.Fprintf(w, "%s\t%d\t%.1f%%\n", status, count, 100*float64(count)/float64(stats.Total))
+	}
+	w.Flush()
+
+	fmt.Printf("\nDuration p50: %v, p90: %v, p99: %v, max: %v\n",
+		stats.Duration.P50, stats.Duration.P90, stats.Duration.P99, stats.Duration.Max)
+
+	if len(stats.Activities) == 0 {
+		return
+	}
+	fmt.Printf("\nActivities from %d histories (%d failed to load)\n", stats.HistoriesRead, stats.HistoryErrors)
+
+	activityTypes := make([]string, 0, len(stats.Activities))
+	for activityType := range stats.Activities {
+		activityTypes = append(activityTypes, activityType)
+	}
+	sort.Strings(activityTypes)
+
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ActivityType\tCount\tFailed\tTimedOut\tFailureRate\tP50\tP90\tP99")
+	for _, activityType := range activityTypes {
+		a := stats.Activities[activityType]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.1f%%\t%v\t%v\t%v\n", activityType, a.Count, a.Failed, a.TimedOut,
+			100*a.FailureRate, a.Latency.P50, a.Latency.P90, a.Latency.P99)
+	}
+	w.Flush()
+}
+
+func latencyStats(values []time.Duration) LatencyStats {
+	if len(values) == 0 {
+		return LatencyStats{}
+	}
+
+	sorted := make([]time.Duration, len(values))
+	copy(sorted, values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return LatencyStats{
+		P50: percentile(sorted, 50),
+		P90: percentile(sorted, 90),
+		P99: percentile(sorted, 99),
+		Max: sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted values
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,52 @@
+package lib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyStats(t *testing.T) {
+	seconds := func(values ...int) []time.Duration {
+		var durations []time.Duration
+		for _, v := range values {
+			durations = append(durations, time.Duration(v)*time.Second)
+		}
+		return durations
+	}
+
+	tests := []struct {
+		name   string
+		values []time.Duration
+		want   LatencyStats
+	}{
+		{name: "empty"},
+		{
+			name:   "single value",
+			values: seconds(3),
+			want:   LatencyStats{P50: 3 * time.Second, P90: 3 * time.Second, P99: 3 * time.Second, Max: 3 * time.Second},
+		},
+		{
+			name:   "nearest rank of unsorted values",
+			values: seconds(7, 1, 10, 4, 2, 9, 3, 8, 6, 5),
+			want:   LatencyStats{P50: 5 * time.Second, P90: 9 * time.Second, P99: 10 * time.Second, Max: 10 * time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := append([]time.Duration(nil), tt.values...)
+			assert.Equal(t, tt.want, latencyStats(tt.values))
+			// the values are left in their order
+			assert.Equal(t, values, tt.values)
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{10, 20, 30, 40}
+	assert.Equal(t, time.Duration(10), percentile(sorted, 0))
+	assert.Equal(t, time.Duration(10), percentile(sorted, 25))
+	assert.Equal(t, time.Duration(20), percentile(sorted, 26))
+	assert.Equal(t, time.Duration(40), percentile(sorted, 100))
+}
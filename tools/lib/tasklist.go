@@ -0,0 +1,132 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli"
+	"github.com/venkat1109/cadence-codelab/cron/jobs"
+	"go.uber.org/cadence/.gen/go/cadence/workflowserviceclient"
+	s "go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/common"
+)
+
+// knownTaskLists are the task lists polled by the workers in this repo, the cron
+// worker also polls a task list per hostgroup of its jobs file
+var knownTaskLists = []string{
+	"cadence-bistro", // eatsapp worker
+	"cron-decider",   // cron decision worker
+}
+
+// DescribeTaskList shows the pollers and backlog of a task list
+func DescribeTaskList(c *cli.Context) {
+	service := getServiceClient(c)
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	taskList := getRequiredOption(c, FlagTaskList)
+	taskListTypes := parseTaskListTypes(c.String(FlagTaskListType))
+
+	for _, tlType := range taskListTypes {
+		resp := describeTaskList(service, domain, taskList, tlType)
+		status := resp.GetTaskListStatus()
+
+		fmt.Printf("TaskList: %s, Type: %v\n", taskList, tlType)
+		fmt.Printf("  BacklogCountHint: %d, ReadLevel: %d, AckLevel: %d, RatePerSecond: %.2f\n",
+			status.GetBacklogCountHint(), status.GetReadLevel(), status.GetAckLevel(), status.GetRatePerSecond())
+
+		pollers := resp.GetPollers()
+		if len(pollers) == 0 {
+			fmt.Println("  No pollers, is a worker running for this task list?")
+			continue
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  Identity\tLastAccessTime\tRatePerSecond")
+		for _, p := range pollers {
+			fmt.Fprintf(w, "  %s\t%s\t%.2f\n", p.GetIdentity(), convertTime(p.GetLastAccessTime()), p.GetRatePerSecond())
+		}
+		w.Flush()
+	}
+}
+
+// ListTaskLists shows a poller and backlog overview for all the task lists used by
+// the workers in this repo, or for the comma separated task lists passed by the user
+func ListTaskLists(c *cli.Context) {
+	service := getServiceClient(c)
+	domain := getRequiredGlobalOption(c, FlagDomain)
+
+	var taskLists []string
+	if c.IsSet(FlagTaskList) {
+		taskLists = strings.Split(c.String(FlagTaskList), ",")
+	} else {
+		taskLists = append(append(taskLists, knownTaskLists...), cronHostgroups(c.String(FlagJobsFile))...)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TaskList\tType\tPollers\tLastAccessTime\tBacklogCountHint")
+	for _, taskList := range taskLists {
+		taskList = strings.TrimSpace(taskList)
+		for _, tlType := range []s.TaskListType{s.TaskListTypeDecision, s.TaskListTypeActivity} {
+			resp := describeTaskList(service, domain, taskList, tlType)
+
+			lastAccess := "-"
+			var latest int64
+			for _, p := range resp.GetPollers() {
+				if p.GetLastAccessTime() > latest {
+					latest = p.GetLastAccessTime()
+				}
+			}
+			if latest > 0 {
+				lastAccess = convertTime(latest)
+			}
+
+			fmt.Fprintf(w, "%s\t%v\t%d\t%s\t%d\n", taskList, tlType, len(resp.GetPollers()),
+				lastAccess, resp.GetTaskListStatus().GetBacklogCountHint())
+		}
+	}
+	w.Flush()
+}
+
+func describeTaskList(
+	service workflowserviceclient.Interface, domain string, taskList string, tlType s.TaskListType) *s.DescribeTaskListResponse {
+	request := &s.DescribeTaskListRequest{
+		Domain:                common.StringPtr(domain),
+		TaskList:              &s.TaskList{Name: common.StringPtr(taskList)},
+		TaskListType:          tlType.Ptr(),
+		IncludeTaskListStatus: common.BoolPtr(true),
+	}
+
+	ctx, cancel := newContext()
+	defer cancel()
+	resp, err := service.DescribeTaskList(ctx, request)
+	if err != nil {
+		ExitIfError(fmt.Errorf("describe task list %s failed: %v", taskList, err))
+	}
+	return resp
+}
+
+// parseTaskListTypes returns the task list types to describe, both when unset
+func parseTaskListTypes(value string) []s.TaskListType {
+	switch strings.ToLower(value) {
+	case "":
+		return []s.TaskListType{s.TaskListTypeDecision, s.TaskListTypeActivity}
+	case "decision":
+		return []s.TaskListType{s.TaskListTypeDecision}
+	case "activity":
+		return []s.TaskListType{s.TaskListTypeActivity}
+	}
+	ExitIfError(fmt.Errorf("invalid %s '%s', must be decision or activity", FlagTaskListType, value))
+	return nil
+}
+
+// cronHostgroups returns the hostgroup task lists polled by the cron worker started
+// with the given jobs file. They are left out when the file cannot be loaded.
+func cronHostgroups(jobsFile string) []string {
+	file, err := jobs.Load(jobsFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Skipping the cron hostgroups: %v\n", err)
+		return nil
+	}
+	return file.Hostgroups()
+}
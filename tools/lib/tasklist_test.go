@@ -0,0 +1,38 @@
+package lib
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	s "go.uber.org/cadence/.gen/go/shared"
+)
+
+func TestParseTaskListTypes(t *testing.T) {
+	assert.Equal(t, []s.TaskListType{s.TaskListTypeDecision, s.TaskListTypeActivity}, parseTaskListTypes(""))
+	assert.Equal(t, []s.TaskListType{s.TaskListTypeDecision}, parseTaskListTypes("Decision"))
+	assert.Equal(t, []s.TaskListType{s.TaskListTypeActivity}, parseTaskListTypes("activity"))
+}
+
+func TestCronHostgroups(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tasklist")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "jobs.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`jobs:
+- name: export
+  schedule: "0 2 * * *"
+  hostgroups: [db-1, db-2]
+  command: /bin/true
+- name: cleanup
+  frequency: 1h
+  hostgroups: [db-2, web-1]
+  command: /bin/true
+`), 0600))
+	assert.Equal(t, []string{"db-1", "db-2", "web-1"}, cronHostgroups(path))
+	assert.Empty(t, cronHostgroups(filepath.Join(dir, "missing.yaml")))
+}
@@ -0,0 +1,390 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli"
+	s "go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/client"
+	"go.uber.org/cadence/common"
+)
+
+const (
+	// topListSize is the number of executions shown in each list of the dashboard
+	topListSize = 10
+	// topDescribeLimit is the number of oldest open executions described on every
+	// refresh to find pending activity retries
+	topDescribeLimit = 50
+	// topFailedWindow is how far back the dashboard looks for failed executions
+	topFailedWindow = 24 * time.Hour
+	clearScreen     = "\033[H\033[2J"
+)
+
+type (
+	// topSnapshot is the state of the domain shown by one refresh of the dashboard
+	topSnapshot struct {
+		takenAt    time.Time
+		openByType map[string]int
+		openTotal  int
+		oldest     []*s.WorkflowExecutionInfo
+		failed     []*s.WorkflowExecutionInfo
+		retrying   []*retryingExecution
+		err        error
+	}
+
+	// retryingExecution is an open execution with an activity that failed at least once
+	retryingExecution struct {
+		info     *s.WorkflowExecutionInfo
+		activity *s.PendingActivityInfo
+	}
+
+	// topRow is a selectable line of the dashboard
+	topRow struct {
+		execution *s.WorkflowExecution
+		line      string
+	}
+)
+
+// Top shows a dashboard of the open workflows of the domain, refreshed every interval.
+// j and k or the arrow keys move the selection, enter describes the selected execution,
+// b goes back, r refreshes and q quits.
+func Top(c *cli.Context) {
+	wfClient := getWorkflowClient(c)
+	interval := time.Duration(c.Int(FlagInterval)) * time.Second
+	if interval <= 0 {
+		ExitIfError(fmt.Errorf("%s must be positive", FlagInterval))
+	}
+	restore, err := enterCbreakMode()
+	if err != nil {
+		ExitIfError(fmt.Errorf("top needs an interactive terminal: %v", err))
+	}
+	defer restore()
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	keys := make(chan string)
+	go readKeys(os.Stdin, keys)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	snapshot := takeSnapshot(wfClient)
+	selected := 0
+	var detail *s.WorkflowExecution
+	for {
+		rows := snapshot.rows()
+		if selected >= len(rows) {
+			selected = len(rows) - 1
+		}
+		if selected < 0 {
+			selected = 0
+		}
+
+		var screen bytes.Buffer
+		if detail != nil {
+			renderDescription(&screen, wfClient, detail)
+		} else {
+			renderSnapshot(&screen, snapshot, rows, selected, interval)
+		}
+		fmt.Print(clearScreen + screen.String())
+
+		select {
+		case <-interrupts:
+			return
+		case <-ticker.C:
+			snapshot = takeSnapshot(wfClient)
+		case key, ok := <-keys:
+			if !ok {
+				// stdin is closed, keep refreshing until interrupted
+				keys = nil
+				continue
+			}
+			switch key {
+			case "q":
+				return
+			case "j", "down":
+				selected++
+			case "k", "up":
+				selected--
+			case "enter":
+				if detail == nil && len(rows) > 0 {
+					detail = rows[selected].execution
+				}
+			case "b", "esc", "backspace":
+				detail = nil
+			case "r":
+				snapshot = takeSnapshot(wfClient)
+			}
+		}
+	}
+}
+
+// takeSnapshot counts the open executions, lists the recently failed ones and
+// describes the oldest open executions to find pending activity retries. Open
+// executions are listed newest first, so all of them are paged through and only
+// the oldest topDescribeLimit are kept.
+func takeSnapshot(wfClient client.Client) *topSnapshot {
+	snapshot := &topSnapshot{takenAt: time.Now(), openByType: make(map[string]int)}
+
+	var oldest []*s.WorkflowExecutionInfo
+	var nextPageToken []byte
+	for {
+		request := &s.ListOpenWorkflowExecutionsRequest{
+			MaximumPageSize: common.Int32Ptr(100),
+			NextPageToken:   nextPageToken,
+			StartTimeFilter: &s.StartTimeFilter{
+				EarliestTime: common.Int64Ptr(0),
+				LatestTime:   common.Int64Ptr(snapshot.takenAt.UnixNano()),
+			},
+		}
+		ctx, cancel := newContext()
+		resp, err := wfClient.ListOpenWorkflow(ctx, request)
+		cancel()
+		if err != nil {
+			snapshot.err = err
+			return snapshot
+		}
+		for _, e := range resp.GetExecutions() {
+			snapshot.openTotal++
+			snapshot.openByType[e.GetType().GetName()]++
+		}
+		oldest = append(oldest, resp.GetExecutions()...)
+		sort.Slice(oldest, func(i, j int) bool { return oldest[i].GetStartTime() < oldest[j].GetStartTime() })
+		oldest = oldest[:minInt(len(oldest), topDescribeLimit)]
+
+		nextPageToken = resp.GetNextPageToken()
+		if len(nextPageToken) == 0 {
+			break
+		}
+	}
+	snapshot.oldest = oldest[:minInt(len(oldest), topListSize)]
+
+	for _, e := range oldest {
+		ctx, cancel := newContext()
+		resp, err := wfClient.DescribeWorkflowExecution(ctx, e.GetExecution().GetWorkflowId(), e.GetExecution().GetRunId())
+		cancel()
+		if err != nil {
+			continue
+		}
+		for _, activity := range resp.GetPendingActivities() {
+			if activity.GetAttempt() > 0 {
+				snapshot.retrying = append(snapshot.retrying, &retryingExecution{info: e, activity: activity})
+			}
+		}
+	}
+
+	snapshot.retrying = snapshot.retrying[:minInt(len(snapshot.retrying), topListSize)]
+
+	for _, status := range []s.WorkflowExecutionCloseStatus{s.WorkflowExecutionCloseStatusFailed, s.WorkflowExecutionCloseStatusTimedOut} {
+		request := &s.ListClosedWorkflowExecutionsRequest{
+			MaximumPageSize: common.Int32Ptr(topListSize),
+			StartTimeFilter: &s.StartTimeFilter{
+				EarliestTime: common.Int64Ptr(snapshot.takenAt.Add(-topFailedWindow).UnixNano()),
+				LatestTime:   common.Int64Ptr(snapshot.takenAt.UnixNano()),
+			},
+			StatusFilter: status.Ptr(),
+		}
+		ctx, cancel := newContext()
+		resp, err := wfClient.ListClosedWorkflow(ctx, request)
+		cancel()
+		if err != nil {
+			snapshot.err = err
+			return snapshot
+		}
+		snapshot.failed = append(snapshot.failed, resp.GetExecutions()...)
+	}
+	sort.Slice(snapshot.failed, func(i, j int) bool {
+		return snapshot.failed[i].GetCloseTime() > snapshot.failed[j].GetCloseTime()
+	})
+	snapshot.failed = snapshot.failed[:minInt(len(snapshot.failed), topListSize)]
+	return snapshot
+}
+
+// rows returns the selectable executions of the snapshot in the order they are rendered
+func (snapshot *topSnapshot) rows() []*topRow {
+	var rows []*topRow
+	now := snapshot.takenAt.UnixNano()
+	for _, e := range snapshot.oldest {
+		rows = append(rows, &topRow{
+			execution: e.GetExecution(),
+			line: fmt.Sprintf("%s\t%s\t%s\trunning %s", e.GetType().GetName(), e.GetExecution().GetWorkflowId(),
+				convertTime(e.GetStartTime()), formatDuration(now-e.GetStartTime())),
+		})
+	}
+	for _, e := range snapshot.failed {
+		rows = append(rows, &topRow{
+			execution: e.GetExecution(),
+			line: fmt.Sprintf("%s\t%s\t%s\t%v", e.GetType().GetName(), e.GetExecution().GetWorkflowId(),
+				convertTime(e.GetCloseTime()), e.GetCloseStatus()),
+		})
+	}
+	for _, r := range snapshot.retrying {
+		rows = append(rows, &topRow{
+			execution: r.info.GetExecution(),
+			line: fmt.Sprintf("%s\t%s\t%s attempt %d\t%s", r.info.GetType().GetName(), r.info.GetExecution().GetWorkflowId(),
+				r.activity.GetActivityType().GetName(), r.activity.GetAttempt()+1, r.activity.GetLastFailureReason()),
+		})
+	}
+	return rows
+}
+
+func renderSnapshot(w io.Writer, snapshot *topSnapshot, rows []*topRow, selected int, interval time.Duration) {
+	fmt.Fprintf(w, "cadence top, %s, refresh every %v, j/k move, enter describe, r refresh, q quit\n\n",
+		snapshot.takenAt.Format(time.RFC3339), interval)
+	if snapshot.err != nil {
+		fmt.Fprintf(w, "Refresh failed: %v\n", snapshot.err)
+		return
+	}
+
+	fmt.Fprintf(w, "Open workflows: %d\n", snapshot.openTotal)
+
+	types := make([]string, 0, len(snapshot.openByType))
+	for workflowType := range snapshot.openByType {
+		types = append(types, workflowType)
+	}
+	sort.Slice(types, func(i, j int) bool { return snapshot.openByType[types[i]] > snapshot.openByType[types[j]] })
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, workflowType := range types {
+		fmt.Fprintf(tw, "  %s\t%d\n", workflowType, snapshot.openByType[workflowType])
+	}
+	tw.Flush()
+
+	sections := []struct {
+		title  string
+		header string
+		size   int
+	}{
+		{"Oldest open executions", "WorkflowType\tWorkflowID\tStartTime\tAge", len(snapshot.oldest)},
+		{fmt.Sprintf("Failed in the last %v", topFailedWindow), "WorkflowType\tWorkflowID\tCloseTime\tCloseStatus", len(snapshot.failed)},
+		{"Pending activity retries", "WorkflowType\tWorkflowID\tActivity\tLastFailure", len(snapshot.retrying)},
+	}
+	index := 0
+	for _, section := range sections {
+		fmt.Fprintf(w, "\n%s\n", section.title)
+		if section.size == 0 {
+			fmt.Fprintln(w, "  none")
+			continue
+		}
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "  %s\n", section.header)
+		for i := 0; i < section.size; i++ {
+			marker := " "
+			if index == selected {
+				marker = ">"
+			}
+			fmt.Fprintf(tw, "%s %s\n", marker, rows[index].line)
+			index++
+		}
+		tw.Flush()
+	}
+}
+
+// renderDescription shows the execution info, pending activities and pending children of an execution
+func renderDescription(w io.Writer, wfClient client.Client, execution *s.WorkflowExecution) {
+	fmt.Fprintf(w, "%s, run %s, b back, q quit\n\n", execution.GetWorkflowId(), execution.GetRunId())
+
+	ctx, cancel := newContext()
+	defer cancel()
+	resp, err := wfClient.DescribeWorkflowExecution(ctx, execution.GetWorkflowId(), execution.GetRunId())
+	if err != nil {
+		fmt.Fprintf(w, "Describe failed: %v\n", err)
+		return
+	}
+
+	info := resp.GetWorkflowExecutionInfo()
+	fmt.Fprintf(w, "Type: %s\nStartTime: %s\n", info.GetType().GetName(), convertTime(info.GetStartTime()))
+	if info.CloseStatus != nil {
+		fmt.Fprintf(w, "CloseTime: %s\nCloseStatus: %v\n", convertTime(info.GetCloseTime()), info.GetCloseStatus())
+	}
+	fmt.Fprintf(w, "HistoryLength: %d\n", info.GetHistoryLength())
+
+	if activities := resp.GetPendingActivities(); len(activities) > 0 {
+		fmt.Fprintln(w, "\nPending activities")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  ActivityID\tType\tState\tAttempt\tLastHeartbeat\tLastFailure")
+		for _, a := range activities {
+			heartbeat := "-"
+			if a.GetLastHeartbeatTimestamp() > 0 {
+				heartbeat = convertTime(a.GetLastHeartbeatTimestamp())
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%v\t%d\t%s\t%s\n", a.GetActivityID(), a.GetActivityType().GetName(),
+				a.GetState(), a.GetAttempt()+1, heartbeat, strings.TrimSpace(a.GetLastFailureReason()))
+		}
+		tw.Flush()
+	}
+
+	if children := resp.GetPendingChildren(); len(children) > 0 {
+		fmt.Fprintln(w, "\nPending children")
+		for _, child := range children {
+			fmt.Fprintf(w, "  %s, -w %s -r %s\n", child.GetWorkflowTypName(), child.GetWorkflowID(), child.GetRunID())
+		}
+	}
+}
+
+// readKeys sends the keys typed on the terminal, naming the arrow and control keys
+func readKeys(reader io.Reader, keys chan<- string) {
+	buf := make([]byte, 8)
+	for {
+		n, err := reader.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		switch {
+		case n >= 3 && buf[0] == 27 && buf[1] == '[' && buf[2] == 'A':
+			keys <- "up"
+		case n >= 3 && buf[0] == 27 && buf[1] == '[' && buf[2] == 'B':
+			keys <- "down"
+		case buf[0] == 27:
+			keys <- "esc"
+		case buf[0] == '\r' || buf[0] == '\n':
+			keys <- "enter"
+		case buf[0] == 127 || buf[0] == 8:
+			keys <- "backspace"
+		default:
+			keys <- string(buf[:1])
+		}
+	}
+}
+
+// enterCbreakMode puts the terminal in cbreak mode without echo so that keys are read
+// as they are typed, and returns the function restoring the previous mode
+func enterCbreakMode() (func(), error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("cbreak", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() {
+		stty(strings.TrimSpace(state))
+		fmt.Println()
+	}, nil
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
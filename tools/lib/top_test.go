@@ -0,0 +1,73 @@
+package lib
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	s "go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/common"
+)
+
+// keyReader returns one key press per read, as a terminal in cbreak mode does
+type keyReader struct {
+	presses [][]byte
+}
+
+func (r *keyReader) Read(buf []byte) (int, error) {
+	if len(r.presses) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(buf, r.presses[0])
+	r.presses = r.presses[1:]
+	return n, nil
+}
+
+func TestReadKeys(t *testing.T) {
+	reader := &keyReader{presses: [][]byte{
+		[]byte("\033[A"), []byte("\033[B"), []byte("\033"), []byte("\n"), {127}, []byte("q"),
+	}}
+	keys := make(chan string, 10)
+	readKeys(reader, keys)
+
+	var read []string
+	for key := range keys {
+		read = append(read, key)
+	}
+	assert.Equal(t, []string{"up", "down", "esc", "enter", "backspace", "q"}, read)
+}
+
+func TestTopRows(t *testing.T) {
+	takenAt := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	execution := func(wid string) *s.WorkflowExecutionInfo {
+		return &s.WorkflowExecutionInfo{
+			Execution: &s.WorkflowExecution{WorkflowId: common.StringPtr(wid), RunId: common.StringPtr("run-" + wid)},
+			Type:      &s.WorkflowType{Name: common.StringPtr("eats")},
+			StartTime: common.Int64Ptr(takenAt.Add(-90 * time.Second).UnixNano()),
+		}
+	}
+	snapshot := &topSnapshot{
+		takenAt: takenAt,
+		oldest:  []*s.WorkflowExecutionInfo{execution("open-1")},
+		failed:  []*s.WorkflowExecutionInfo{execution("failed-1")},
+		retrying: []*retryingExecution{{
+			info: execution("retrying-1"),
+			activity: &s.PendingActivityInfo{
+				ActivityType:      &s.ActivityType{Name: common.StringPtr("placeOrder")},
+				Attempt:           common.Int32Ptr(2),
+				LastFailureReason: common.StringPtr("restaurant closed"),
+			},
+		}},
+	}
+
+	rows := snapshot.rows()
+	require.Len(t, rows, 3)
+	// open executions come first, then the failed and the retrying ones
+	assert.Equal(t, "open-1", rows[0].execution.GetWorkflowId())
+	assert.Contains(t, rows[0].line, "running 1m30s")
+	assert.Equal(t, "failed-1", rows[1].execution.GetWorkflowId())
+	assert.Equal(t, "retrying-1", rows[2].execution.GetWorkflowId())
+	assert.Contains(t, rows[2].line, "placeOrder attempt 3\trestaurant closed")
+}